@@ -0,0 +1,193 @@
+package bio
+
+import (
+	"fmt"
+	"strings"
+)
+
+// aa26.go
+//
+// Extended amino acid alphabet and three-letter codes.
+
+// AA26 type holds amino acid sequences that may include the extended
+// symbols of AA26Alphabet.
+//
+// Beyond the 20 symbols of AA20Alphabet these are U (selenocysteine),
+// O (pyrrolysine), and the ambiguity symbols B (D or N), Z (E or Q),
+// J (I or L), and X (any).  Content should be upper case.  Translation
+// may also produce the stop symbol AAStop.
+type AA26 []byte
+
+// AA26Alphabet holds the IUPAC amino acid symbols including the rare and
+// ambiguity symbols.  It is the upper case alphabet.
+var AA26Alphabet = AA26("ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+// String returns an AA26 converted to a string.
+func (s AA26) String() string {
+	return string(s)
+}
+
+// AA26 returns the receiver converted to AA26.
+//
+// The underlying array is shared.
+func (s AA20) AA26() AA26 {
+	return AA26(s)
+}
+
+// AA20 converts an AA26 sequence to AA20.
+//
+// An error is returned if the sequence contains symbols outside of
+// AA20Alphabet.  Otherwise the underlying array is shared.
+func (s AA26) AA20() (AA20, error) {
+	for i, aa := range s {
+		if aa < 'A' || aa > 'Z' || aa26Index[aa-'A'] < 0 {
+			return nil, fmt.Errorf("symbol %q at position %d not in AA20Alphabet",
+				aa, i)
+		}
+	}
+	return AA20(s), nil
+}
+
+// aa26Index maps symbol - 'A' to the index of the symbol in AA20Alphabet,
+// or -1 if the symbol is not in AA20Alphabet.
+var aa26Index [26]int
+
+// aa3 holds three-letter codes indexed by symbol - 'A'.
+var aa3 = [26]string{
+	"Ala", "Asx", "Cys", "Asp", "Glu", "Phe", "Gly", "His", "Ile", "Xle",
+	"Lys", "Leu", "Met", "Asn", "Pyl", "Pro", "Gln", "Arg", "Ser", "Thr",
+	"Sec", "Val", "Trp", "Xaa", "Tyr", "Glx",
+}
+
+// aa1 maps upper case three-letter codes to one-letter symbols.
+var aa1 = map[string]byte{"TER": AAStop}
+
+func init() {
+	for i := range aa26Index {
+		aa26Index[i] = -1
+	}
+	for i, aa := range AA20Alphabet {
+		aa26Index[aa-'A'] = i
+	}
+	for i, c := range aa3 {
+		aa1[strings.ToUpper(c)] = 'A' + byte(i)
+	}
+}
+
+// AAThreeLetter returns the three-letter code for an amino acid symbol.
+//
+// Symbols of AA26Alphabet give codes with an initial capital such as
+// "Ala".  AAStop gives "Ter".  Other symbols give an empty string.
+func AAThreeLetter(aa byte) string {
+	switch {
+	case aa == AAStop:
+		return "Ter"
+	case aa >= 'A' && aa <= 'Z':
+		return aa3[aa-'A']
+	}
+	return ""
+}
+
+// AAOneLetter returns the one-letter symbol for a three-letter code.
+//
+// Case is ignored.  "Ter" gives AAStop.  Ok is false for unknown codes.
+func AAOneLetter(code string) (aa byte, ok bool) {
+	aa, ok = aa1[strings.ToUpper(code)]
+	return
+}
+
+// ThreeLetter returns the receiver sequence as three-letter codes
+// separated by sep.
+//
+// Symbols without a three-letter code are written as "???".
+func (s AA26) ThreeLetter(sep string) string {
+	c := make([]string, len(s))
+	for i, aa := range s {
+		if c[i] = AAThreeLetter(aa); c[i] == "" {
+			c[i] = "???"
+		}
+	}
+	return strings.Join(c, sep)
+}
+
+// ParseThreeLetter parses a sequence of three-letter amino acid codes.
+//
+// Codes may be run together as in "AlaGlyTer" or separated by white space
+// or hyphens as in "ALA GLY" or "Ala-Gly".  Case is ignored.
+func ParseThreeLetter(s string) (AA26, error) {
+	f := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	var p AA26
+	for _, w := range f {
+		if len(w)%3 != 0 {
+			return nil, fmt.Errorf("invalid three-letter code sequence %q", w)
+		}
+		for i := 0; i < len(w); i += 3 {
+			aa, ok := AAOneLetter(w[i : i+3])
+			if !ok {
+				return nil, fmt.Errorf("unknown three-letter code %q", w[i:i+3])
+			}
+			p = append(p, aa)
+		}
+	}
+	return p, nil
+}
+
+// TranslateCodon16 translates a codon of three DNA16 base sets.
+//
+// The codon is expanded to all DNA codons it represents and the result is
+// the single amino acid or AAStop they all translate to, B, Z, or J if they
+// translate to the two amino acids of one of these symbols, and X
+// otherwise.  A codon containing a gap translates as X.
+func (c *GeneticCode) TranslateCodon16(b0, b1, b2 byte) byte {
+	var set [256]bool
+	var r []byte
+	for i0 := uint(0); i0 < 4; i0++ {
+		for i1 := uint(0); i1 < 4; i1++ {
+			for i2 := uint(0); i2 < 4; i2++ {
+				if b0>>i0&1 == 0 || b1>>i1&1 == 0 || b2>>i2&1 == 0 {
+					continue
+				}
+				aa := c.TranslateCodon("ACGT"[i0], "ACGT"[i1], "ACGT"[i2])
+				if !set[aa] {
+					set[aa] = true
+					r = append(r, aa)
+				}
+			}
+		}
+	}
+	switch {
+	case len(r) == 1:
+		return r[0]
+	case len(r) != 2:
+	case set['D'] && set['N']:
+		return 'B'
+	case set['E'] && set['Q']:
+		return 'Z'
+	case set['I'] && set['L']:
+		return 'J'
+	}
+	return 'X'
+}
+
+// TranslateCode translates the receiver DNA16 sequence into an amino acid
+// sequence using genetic code c.
+//
+// Codons are translated with GeneticCode.TranslateCodon16.  Translation
+// ends at a codon that translates to AAStop or at the end of the sequence.
+// Return value stop is true if a stop codon was present.  If c is nil the
+// standard code is used.
+func (s DNA16) TranslateCode(c *GeneticCode) (p AA26, stop bool) {
+	if c == nil {
+		c = StandardCode
+	}
+	for i := 2; i < len(s); i += 3 {
+		aa := c.TranslateCodon16(s[i-2], s[i-1], s[i])
+		if aa == AAStop {
+			return p, true
+		}
+		p = append(p, aa)
+	}
+	return p, false
+}
@@ -0,0 +1,75 @@
+package bio_test
+
+import (
+	"fmt"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleAAThreeLetter() {
+	fmt.Println(bio.AAThreeLetter('W'), bio.AAThreeLetter('U'),
+		bio.AAThreeLetter('J'), bio.AAThreeLetter(bio.AAStop))
+	fmt.Println(bio.AAOneLetter("TRP"))
+	fmt.Println(bio.AAOneLetter("Foo"))
+	// Output:
+	// Trp Sec Xle Ter
+	// 87 true
+	// 0 false
+}
+
+func ExampleAA26_ThreeLetter() {
+	fmt.Println(bio.AA26("MUOBZX").ThreeLetter("-"))
+	// Output:
+	// Met-Sec-Pyl-Asx-Glx-Xaa
+}
+
+func ExampleParseThreeLetter() {
+	p, err := bio.ParseThreeLetter("MetGlySec-Lys\nALA pyl TER")
+	fmt.Println(p, err)
+	_, err = bio.ParseThreeLetter("MetGl")
+	fmt.Println(err)
+	// Output:
+	// MGUKAO* <nil>
+	// invalid three-letter code sequence "MetGl"
+}
+
+func ExampleAA26_AA20() {
+	p, err := bio.AA26("MAG").AA20()
+	fmt.Println(p, err)
+	_, err = bio.AA26("MAGU").AA20()
+	fmt.Println(err)
+	// Output:
+	// MAG <nil>
+	// symbol 'U' at position 3 not in AA20Alphabet
+}
+
+func ExampleAA26_Weight() {
+	fmt.Printf("%.5f\n", bio.AA26("GU").Weight())
+	fmt.Printf("%.5f\n", bio.AA26("GJ").Weight()-bio.AA20("GL").Weight())
+	fmt.Println(bio.AA26("GX").Weight())
+	// Output:
+	// 225.98566
+	// 0.00000
+	// NaN
+}
+
+func ExampleGeneticCode_TranslateCodon16() {
+	for _, c := range []string{"ATG", "YTR", "RAY", "MTT", "TAR", "NNN"} {
+		d, _ := bio.ParseDNA16(c)
+		fmt.Printf("%s %c\n", c, bio.StandardCode.TranslateCodon16(d[0], d[1], d[2]))
+	}
+	// Output:
+	// ATG M
+	// YTR L
+	// RAY B
+	// MTT J
+	// TAR *
+	// NNN X
+}
+
+func ExampleDNA16_TranslateCode() {
+	d, _ := bio.ParseDNA16("ATGSARGAYTRRCCN")
+	fmt.Println(d.TranslateCode(nil))
+	// Output:
+	// MZDXP false
+}
@@ -56,6 +56,45 @@ func init() {
 	AA20MonoisotopicMassTable['Y'-'A'] = 163.06333
 }
 
+// AA26MonoisotopicMassTable holds monoisotopic residue masses indexed by
+// symbol - 'A'.
+//
+// Masses of the 20 amino acids are those of AA20MonoisotopicMassTable.
+// B and Z have the mean mass of their two amino acids, J has the common
+// mass of I and L, and X is NaN.
+var AA26MonoisotopicMassTable [26]float64
+
+// Note: this must follow init of AA20MonoisotopicMassTable.
+func init() {
+	copy(AA26MonoisotopicMassTable[:], AA20MonoisotopicMassTable[:])
+	t := &AA26MonoisotopicMassTable
+	t['U'-'A'] = 150.95364
+	t['O'-'A'] = 237.14773
+	t['B'-'A'] = (t['D'-'A'] + t['N'-'A']) / 2
+	t['Z'-'A'] = (t['E'-'A'] + t['Q'-'A']) / 2
+	t['J'-'A'] = t['L'-'A']
+	t['X'-'A'] = math.NaN()
+}
+
+// AA26MonoisotopicMass returns the residue mass of an amino acid symbol
+// of AA26Alphabet.
+//
+// Symbols not in AA26Alphabet may cause panic.
+func AA26MonoisotopicMass(aa byte) float64 {
+	return AA26MonoisotopicMassTable[aa-'A']
+}
+
+// Weight returns the monoisotopic weight of the amino acid chain.
+//
+// The result is NaN if the sequence contains X.
+func (a AA26) Weight() float64 {
+	s := WaterMassMonoisotopic
+	for _, aa := range a {
+		s += AA26MonoisotopicMass(aa)
+	}
+	return s
+}
+
 const (
 	AA20Lightest = 'G' // Glycine
 	AA20Heaviest = 'W' // Tryptophan
@@ -0,0 +1,246 @@
+package bio
+
+import "math"
+
+// aaprop.go
+//
+// Physicochemical properties of proteins.
+
+// PKaSet is a set of pKa values for computing charge and isoelectric point.
+//
+// NTerm and CTerm are pKa values of the terminal amino and carboxyl groups.
+// The remaining fields are side chain pKa values.  NTermRes and CTermRes
+// optionally give terminal pKa values that depend on the terminal residue,
+// overriding NTerm and CTerm.
+type PKaSet struct {
+	Name               string
+	NTerm, CTerm       float64
+	K, R, H            float64 // positively charged side chains
+	D, E, C, Y         float64 // negatively charged side chains
+	NTermRes, CTermRes map[byte]float64
+}
+
+// Published pKa sets.
+var (
+	PKaEMBOSS = &PKaSet{Name: "EMBOSS",
+		NTerm: 8.6, CTerm: 3.6,
+		K: 10.8, R: 12.5, H: 6.5, D: 3.9, E: 4.1, C: 8.5, Y: 10.1}
+	PKaLehninger = &PKaSet{Name: "Lehninger",
+		NTerm: 9.69, CTerm: 2.34,
+		K: 10.5, R: 12.4, H: 6.0, D: 3.86, E: 4.25, C: 8.33, Y: 10.0}
+	PKaSolomon = &PKaSet{Name: "Solomon",
+		NTerm: 9.6, CTerm: 2.4,
+		K: 10.5, R: 12.5, H: 6.0, D: 3.9, E: 4.3, C: 8.3, Y: 10.1}
+	PKaSillero = &PKaSet{Name: "Sillero",
+		NTerm: 8.2, CTerm: 3.2,
+		K: 10.4, R: 12.0, H: 6.4, D: 4.0, E: 4.5, C: 9.0, Y: 10.0}
+	PKaDTASelect = &PKaSet{Name: "DTASelect",
+		NTerm: 8.0, CTerm: 3.1,
+		K: 10.0, R: 12.0, H: 6.5, D: 4.4, E: 4.4, C: 8.5, Y: 10.0}
+	// PKaBjellqvist is the set of Bjellqvist et al. 1993 as used by
+	// Biopython, approximating the ExPASy Compute pI/Mw tool.
+	PKaBjellqvist = &PKaSet{Name: "Bjellqvist",
+		NTerm: 7.5, CTerm: 3.55,
+		K: 10.0, R: 12.0, H: 5.98, D: 4.05, E: 4.45, C: 9.0, Y: 10.0,
+		NTermRes: map[byte]float64{'A': 7.59, 'M': 7.0, 'S': 6.93,
+			'P': 8.36, 'T': 6.82, 'V': 7.44, 'E': 7.7},
+		CTermRes: map[byte]float64{'D': 4.55, 'E': 4.75}}
+)
+
+// Charge computes the net charge of the peptide at the given pH.
+//
+// The Henderson-Hasselbalch equation is applied to each ionizable group
+// with pKa values from pk.
+func (a AA20) Charge(pH float64, pk *PKaSet) float64 {
+	if len(a) == 0 {
+		return 0
+	}
+	pos := func(pKa float64) float64 { return 1 / (1 + math.Pow(10, pH-pKa)) }
+	neg := func(pKa float64) float64 { return -1 / (1 + math.Pow(10, pKa-pH)) }
+	n, c := pk.NTerm, pk.CTerm
+	if v, ok := pk.NTermRes[a[0]]; ok {
+		n = v
+	}
+	if v, ok := pk.CTermRes[a[len(a)-1]]; ok {
+		c = v
+	}
+	q := pos(n) + neg(c)
+	for _, r := range a {
+		switch r {
+		case 'K':
+			q += pos(pk.K)
+		case 'R':
+			q += pos(pk.R)
+		case 'H':
+			q += pos(pk.H)
+		case 'D':
+			q += neg(pk.D)
+		case 'E':
+			q += neg(pk.E)
+		case 'C':
+			q += neg(pk.C)
+		case 'Y':
+			q += neg(pk.Y)
+		}
+	}
+	return q
+}
+
+// IsoelectricPoint computes the pH at which the net charge of the peptide
+// is zero, using pKa values from pk.
+//
+// The result is found by bisection over the range 0 to 14 to a precision
+// of 0.0001.
+func (a AA20) IsoelectricPoint(pk *PKaSet) float64 {
+	lo, hi := 0., 14.
+	for hi-lo > 1e-4 {
+		mid := (lo + hi) / 2
+		if a.Charge(mid, pk) > 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// KyteDoolittle holds the hydropathy scale of Kyte and Doolittle 1982,
+// indexed by amino acid symbol - 'A'.
+var KyteDoolittle = [25]float64{
+	'A' - 'A': 1.8, 'R' - 'A': -4.5, 'N' - 'A': -3.5, 'D' - 'A': -3.5,
+	'C' - 'A': 2.5, 'Q' - 'A': -3.5, 'E' - 'A': -3.5, 'G' - 'A': -0.4,
+	'H' - 'A': -3.2, 'I' - 'A': 4.5, 'L' - 'A': 3.8, 'K' - 'A': -3.9,
+	'M' - 'A': 1.9, 'F' - 'A': 2.8, 'P' - 'A': -1.6, 'S' - 'A': -0.8,
+	'T' - 'A': -0.7, 'W' - 'A': -0.9, 'Y' - 'A': -1.3, 'V' - 'A': 4.2,
+}
+
+// GRAVY computes the grand average of hydropathy, the mean Kyte-Doolittle
+// hydropathy of the residues.
+func (a AA20) GRAVY() float64 {
+	s := 0.
+	for _, r := range a {
+		s += KyteDoolittle[r-'A']
+	}
+	return s / float64(len(a))
+}
+
+// HydropathyProfile computes the mean Kyte-Doolittle hydropathy over
+// a sliding window.
+//
+// Element i of the result is the mean over residues i through
+// i+window-1.  The result has len(a)-window+1 elements, or none if the
+// peptide is shorter than the window.
+func (a AA20) HydropathyProfile(window int) []float64 {
+	if window <= 0 || window > len(a) {
+		return nil
+	}
+	p := make([]float64, len(a)-window+1)
+	s := 0.
+	for i, r := range a {
+		s += KyteDoolittle[r-'A']
+		if i >= window {
+			s -= KyteDoolittle[a[i-window]-'A']
+		}
+		if i >= window-1 {
+			p[i-window+1] = s / float64(window)
+		}
+	}
+	return p
+}
+
+// ExtinctionCoefficient computes the molar extinction coefficient at
+// 280 nm in M⁻¹cm⁻¹, as by the method of Pace et al. 1995.
+//
+// Result reduced assumes all cysteines are reduced.  Result cystines
+// assumes all pairs of cysteines form cystines.
+func (a AA20) ExtinctionCoefficient() (reduced, cystines int) {
+	var w, y, c int
+	for _, r := range a {
+		switch r {
+		case 'W':
+			w++
+		case 'Y':
+			y++
+		case 'C':
+			c++
+		}
+	}
+	reduced = 5500*w + 1490*y
+	return reduced, reduced + 125*(c/2)
+}
+
+// diwv holds dipeptide instability weight values of Guruprasad et al. 1990,
+// indexed by the first and second residues of the dipeptide in the order
+// of AA20Alphabet.
+var diwv = [20][20]float64{
+	//A      C      D      E      F      G      H      I      K      L      M      N      P      Q      R      S      T      V      W      Y
+	{1.0, 44.94, -7.49, 1.0, 1.0, 1.0, -7.49, 1.0, 1.0, 1.0, 1.0, 1.0, 20.26, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0},                 // A
+	{1.0, 1.0, 20.26, 1.0, 1.0, 1.0, 33.60, 1.0, 1.0, 20.26, 33.60, 1.0, 20.26, -6.54, 1.0, 1.0, 33.60, -6.54, 24.68, 1.0},       // C
+	{1.0, 1.0, 1.0, 1.0, -6.54, 1.0, 1.0, 1.0, -7.49, 1.0, 1.0, 1.0, 1.0, 1.0, -6.54, 20.26, -14.03, 1.0, 1.0, 1.0},              // D
+	{1.0, 44.94, 20.26, 33.60, 1.0, 1.0, -6.54, 20.26, 1.0, 1.0, 1.0, 1.0, 20.26, 20.26, 1.0, 20.26, 1.0, 1.0, -14.03, 1.0},      // E
+	{1.0, 1.0, 13.34, 1.0, 1.0, 1.0, 1.0, 1.0, -14.03, 1.0, 1.0, 1.0, 20.26, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 33.601},               // F
+	{-7.49, 1.0, 1.0, -6.54, 1.0, 13.34, 1.0, -7.49, -7.49, 1.0, 1.0, -7.49, 1.0, 1.0, 1.0, 1.0, -7.49, 1.0, 13.34, -7.49},       // G
+	{1.0, 1.0, 1.0, 1.0, -9.37, -9.37, 1.0, 44.94, 24.68, 1.0, 1.0, 24.68, -1.88, 1.0, 1.0, 1.0, -6.54, 1.0, -1.88, 44.94},       // H
+	{1.0, 1.0, 1.0, 44.94, 1.0, 1.0, 13.34, 1.0, -7.49, 20.26, 1.0, 1.0, -1.88, 1.0, 1.0, 1.0, 1.0, -7.49, 1.0, 1.0},             // I
+	{1.0, 1.0, 1.0, 1.0, 1.0, -7.49, 1.0, -7.49, 1.0, -7.49, 33.60, 1.0, -6.54, 24.64, 33.60, 1.0, 1.0, -7.49, 1.0, 1.0},         // K
+	{1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, -7.49, 1.0, 1.0, 1.0, 20.26, 33.60, 20.26, 1.0, 1.0, 1.0, 24.68, 1.0},               // L
+	{13.34, 1.0, 1.0, 1.0, 1.0, 1.0, 58.28, 1.0, 1.0, 1.0, -1.88, 1.0, 44.94, -6.54, -6.54, 44.94, -1.88, 1.0, 1.0, 24.68},       // M
+	{1.0, -1.88, 1.0, 1.0, -14.03, -14.03, 1.0, 44.94, 24.68, 1.0, 1.0, 1.0, -1.88, -6.54, 1.0, 1.0, -7.49, 1.0, -9.37, 1.0},     // N
+	{20.26, -6.54, -6.54, 18.38, 20.26, 1.0, 1.0, 1.0, 1.0, 1.0, -6.54, 1.0, 20.26, 20.26, -6.54, 20.26, 1.0, 20.26, -1.88, 1.0}, // P
+	{1.0, -6.54, 20.26, 20.26, -6.54, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 20.26, 20.26, 1.0, 44.94, 1.0, -6.54, 1.0, -6.54},       // Q
+	{1.0, 1.0, 1.0, 1.0, 1.0, -7.49, 20.26, 1.0, 1.0, 1.0, 1.0, 13.34, 20.26, 20.26, 58.28, 44.94, 1.0, 1.0, 58.28, -6.54},       // R
+	{1.0, 33.60, 1.0, 20.26, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 44.94, 20.26, 20.26, 20.26, 1.0, 1.0, 1.0, 1.0},             // S
+	{1.0, 1.0, 1.0, 20.26, 13.34, -7.49, 1.0, 1.0, 1.0, 1.0, 1.0, -14.03, 1.0, -6.54, 1.0, 1.0, 1.0, 1.0, -14.03, 1.0},           // T
+	{1.0, 1.0, -14.03, 1.0, 1.0, -7.49, 1.0, 1.0, -1.88, 1.0, 1.0, 1.0, 20.26, 1.0, 1.0, 1.0, -7.49, 1.0, 1.0, -6.54},            // V
+	{-14.03, 1.0, 1.0, 1.0, 1.0, -9.37, 24.68, 1.0, 1.0, 13.34, 24.68, 13.34, 1.0, 1.0, 1.0, 1.0, -14.03, -7.49, 1.0, 1.0},       // W
+	{24.68, 1.0, 24.68, -6.54, 1.0, -7.49, 13.34, 1.0, 1.0, 1.0, 44.94, 1.0, 13.34, 1.0, -15.91, 1.0, -7.49, 1.0, -9.37, 13.34},  // Y
+}
+
+// aa20Index maps amino acid symbols to indexes in AA20Alphabet.
+var aa20Index [256]int
+
+func init() {
+	for i, r := range AA20Alphabet {
+		aa20Index[r] = i
+	}
+}
+
+// InstabilityIndex computes the instability index of Guruprasad et al.
+// 1990.
+//
+// Proteins with an index above 40 are predicted to be unstable.
+func (a AA20) InstabilityIndex() float64 {
+	s := 0.
+	for i := 1; i < len(a); i++ {
+		s += diwv[aa20Index[a[i-1]]][aa20Index[a[i]]]
+	}
+	return 10 / float64(len(a)) * s
+}
+
+// AliphaticIndex computes the aliphatic index of Ikai 1980, the relative
+// volume occupied by aliphatic side chains.
+func (a AA20) AliphaticIndex() float64 {
+	var ala, val, ile int
+	for _, r := range a {
+		switch r {
+		case 'A':
+			ala++
+		case 'V':
+			val++
+		case 'I', 'L':
+			ile++
+		}
+	}
+	n := float64(len(a)) / 100 // for mole percent
+	return (float64(ala) + 2.9*float64(val) + 3.9*float64(ile)) / n
+}
+
+// Composition counts the occurrences of each amino acid.
+//
+// The result is indexed by amino acid symbol - 'A'.
+func (a AA20) Composition() (c [25]int) {
+	for _, r := range a {
+		c[r-'A']++
+	}
+	return
+}
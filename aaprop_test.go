@@ -0,0 +1,66 @@
+package bio_test
+
+import (
+	"fmt"
+
+	"github.com/soniakeys/bio"
+)
+
+// Human ubiquitin, with values as published by ExPASy ProtParam.
+var ubiquitin = bio.AA20("MQIFVKTLTGKTITLEVEPSDTIENVKAKIQDKEGIPPDQQRLIFAGKQLEDGRTLSDYNIQKESTLHLVLRLRGG")
+
+func ExampleAA20_IsoelectricPoint() {
+	fmt.Printf("%.2f\n", ubiquitin.IsoelectricPoint(bio.PKaBjellqvist))
+	fmt.Printf("%.2f\n", ubiquitin.IsoelectricPoint(bio.PKaEMBOSS))
+	// Output:
+	// 6.56
+	// 7.54
+}
+
+func ExampleAA20_Charge() {
+	fmt.Printf("%.2f\n", bio.AA20("DE").Charge(7, bio.PKaLehninger))
+	// Output:
+	// -2.00
+}
+
+func ExampleAA20_GRAVY() {
+	fmt.Printf("%.3f\n", ubiquitin.GRAVY())
+	// Output:
+	// -0.489
+}
+
+func ExampleAA20_HydropathyProfile() {
+	for _, h := range bio.AA20("IVLKDE").HydropathyProfile(3) {
+		fmt.Printf("%.2f\n", h)
+	}
+	// Output:
+	// 4.17
+	// 1.37
+	// -1.20
+	// -3.63
+}
+
+func ExampleAA20_ExtinctionCoefficient() {
+	fmt.Println(ubiquitin.ExtinctionCoefficient())
+	// Output:
+	// 1490 1490
+}
+
+func ExampleAA20_InstabilityIndex() {
+	fmt.Printf("%.2f\n", ubiquitin.InstabilityIndex())
+	// Output:
+	// 36.06
+}
+
+func ExampleAA20_AliphaticIndex() {
+	fmt.Printf("%.2f\n", ubiquitin.AliphaticIndex())
+	// Output:
+	// 100.00
+}
+
+func ExampleAA20_Composition() {
+	c := bio.AA20("MAGMA").Composition()
+	fmt.Println(c['A'-'A'], c['G'-'A'], c['M'-'A'])
+	// Output:
+	// 2 1 2
+}
@@ -0,0 +1,167 @@
+package bio
+
+// AhoCorasick is an Aho-Corasick automaton for searching text for many
+// patterns at once.
+//
+// Member P holds the patterns passed to the constructor.  As with KMP,
+// the automaton will not work if the patterns are subsequently modified.
+type AhoCorasick struct {
+	P     []Seq
+	fold  bool
+	delta []int32 // transitions, 256 per state
+	out   [][]int // indexes into pats of patterns ending at each state
+	dict  []int32 // dictionary suffix link, next state with output or -1
+	pats  []acPat
+}
+
+// acPat is a pattern as entered into the automaton.
+type acPat struct {
+	id     int  // index into P
+	n      int  // length
+	strand byte // '+', or '-' for a reverse complement
+}
+
+// AhoCorasickOptions control NewAhoCorasick.
+type AhoCorasickOptions struct {
+	// FoldCase matches ASCII letters case insensitively.
+	FoldCase bool
+	// RevComp also searches for the reverse complements of the patterns,
+	// which should be DNA8.
+	RevComp bool
+}
+
+// ACMatch is a match found by an AhoCorasick automaton.
+type ACMatch struct {
+	Pattern int  // index of the pattern in AhoCorasick.P
+	Pos     int  // position of the start of the match in the text
+	Strand  byte // '+', or '-' for a match of the reverse complement
+}
+
+// NewAhoCorasick constructs an Aho-Corasick automaton from a set of
+// patterns.
+//
+// Empty patterns are ignored.  Construction time and memory are
+// proportional to the total length of the patterns.
+func NewAhoCorasick(p []Seq, opt AhoCorasickOptions) *AhoCorasick {
+	a := &AhoCorasick{P: p, fold: opt.FoldCase}
+	a.newState()
+	add := func(s Seq, id int, strand byte) {
+		if len(s) == 0 {
+			return
+		}
+		st := int32(0)
+		for _, b := range s {
+			b = a.foldByte(b)
+			if a.delta[int(st)<<8|int(b)] == 0 {
+				a.delta[int(st)<<8|int(b)] = a.newState()
+			}
+			st = a.delta[int(st)<<8|int(b)]
+		}
+		a.out[st] = append(a.out[st], len(a.pats))
+		a.pats = append(a.pats, acPat{id, len(s), strand})
+	}
+	for i, s := range p {
+		add(s, i, '+')
+		if opt.RevComp {
+			add(Seq(DNA8(s).ReverseComplement()), i, '-')
+		}
+	}
+	// breadth first construction of failure transitions.  transitions
+	// that are 0 other than from the root become failure transitions.
+	fail := make([]int32, len(a.out))
+	var q []int32
+	for b := 0; b < 256; b++ {
+		if s := a.delta[b]; s != 0 {
+			q = append(q, s)
+		}
+	}
+	for len(q) > 0 {
+		st := q[0]
+		q = q[1:]
+		f := fail[st]
+		if len(a.out[f]) > 0 {
+			a.dict[st] = f
+		} else {
+			a.dict[st] = a.dict[f]
+		}
+		for b := 0; b < 256; b++ {
+			x := int(st)<<8 | b
+			if s := a.delta[x]; s != 0 {
+				fail[s] = a.delta[int(f)<<8|b]
+				q = append(q, s)
+			} else {
+				a.delta[x] = a.delta[int(f)<<8|b]
+			}
+		}
+	}
+	return a
+}
+
+func (a *AhoCorasick) newState() int32 {
+	a.delta = append(a.delta, make([]int32, 256)...)
+	a.out = append(a.out, nil)
+	a.dict = append(a.dict, -1)
+	return int32(len(a.out) - 1)
+}
+
+func (a *AhoCorasick) foldByte(b byte) byte {
+	if a.fold && b >= 'a' && b <= 'z' {
+		b &^= LCBit
+	}
+	return b
+}
+
+// AllIndex returns all matches of the patterns in t.
+//
+// Matches are ordered by their end position in t, then by decreasing
+// length.
+func (a *AhoCorasick) AllIndex(t Seq) (x []ACMatch) {
+	m := a.NewMatcher(func(r ACMatch) { x = append(x, r) })
+	m.Write(t)
+	return
+}
+
+// ACMatcher is a streaming matcher of an AhoCorasick automaton.
+//
+// Text is supplied by the Write method, which satisfies io.Writer.  Matches
+// are reported as they are found, so matches spanning multiple writes are
+// found.
+type ACMatcher struct {
+	a     *AhoCorasick
+	fn    func(ACMatch)
+	state int32
+	pos   int
+}
+
+// NewMatcher returns a streaming matcher that calls fn for each match.
+func (a *AhoCorasick) NewMatcher(fn func(ACMatch)) *ACMatcher {
+	return &ACMatcher{a: a, fn: fn}
+}
+
+// Write consumes text, calling the match function for each match ending
+// within p.  Positions are relative to the start of all text written
+// since the matcher was constructed or reset.  The error is always nil.
+func (m *ACMatcher) Write(p []byte) (int, error) {
+	a := m.a
+	st := m.state
+	for i, b := range p {
+		st = a.delta[int(st)<<8|int(a.foldByte(b))]
+		end := m.pos + i + 1
+		for s := st; s >= 0; s = a.dict[s] {
+			for _, x := range a.out[s] {
+				ap := a.pats[x]
+				m.fn(ACMatch{ap.id, end - ap.n, ap.strand})
+			}
+		}
+	}
+	m.state = st
+	m.pos += len(p)
+	return len(p), nil
+}
+
+// Reset restores the matcher to its initial state, as if no text had been
+// written.
+func (m *ACMatcher) Reset() {
+	m.state = 0
+	m.pos = 0
+}
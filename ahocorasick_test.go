@@ -0,0 +1,80 @@
+package bio_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleAhoCorasick_AllIndex() {
+	a := bio.NewAhoCorasick([]bio.Seq{
+		bio.Seq("he"),
+		bio.Seq("she"),
+		bio.Seq("his"),
+		bio.Seq("hers"),
+	}, bio.AhoCorasickOptions{})
+	for _, m := range a.AllIndex(bio.Seq("ushers")) {
+		fmt.Println(a.P[m.Pattern], m.Pos)
+	}
+	// Output:
+	// she 1
+	// he 2
+	// hers 2
+}
+
+func ExampleAhoCorasickOptions() {
+	a := bio.NewAhoCorasick([]bio.Seq{
+		bio.Seq("AGATCGG"),
+		bio.Seq("ctgtct"),
+	}, bio.AhoCorasickOptions{FoldCase: true, RevComp: true})
+	for _, m := range a.AllIndex(bio.Seq("ttccgatctAAGACAGctgtctc")) {
+		fmt.Println(a.P[m.Pattern], m.Pos, string(m.Strand))
+	}
+	// Output:
+	// AGATCGG 2 -
+	// ctgtct 10 -
+	// ctgtct 16 +
+}
+
+func ExampleACMatcher() {
+	a := bio.NewAhoCorasick([]bio.Seq{bio.Seq("GATTACA")},
+		bio.AhoCorasickOptions{})
+	m := a.NewMatcher(func(r bio.ACMatch) { fmt.Println("match at", r.Pos) })
+	fmt.Fprint(m, "TTGAT")
+	fmt.Fprint(m, "TACAGG")
+	// Output:
+	// match at 2
+}
+
+func TestAhoCorasick(t *testing.T) {
+	p := make([]bio.Seq, 30)
+	for i := range p {
+		p[i] = make(bio.Seq, 1+rand.Intn(5))
+		for j := range p[i] {
+			p[i][j] = "ACGT"[rand.Intn(4)]
+		}
+	}
+	s := make(bio.Seq, 1000)
+	for i := range s {
+		s[i] = "ACGT"[rand.Intn(4)]
+	}
+	a := bio.NewAhoCorasick(p, bio.AhoCorasickOptions{})
+	got := map[bio.ACMatch]bool{}
+	for _, m := range a.AllIndex(s) {
+		got[m] = true
+	}
+	n := 0
+	for i, pi := range p {
+		for _, x := range s.AllIndex(pi) {
+			n++
+			if !got[bio.ACMatch{i, x, '+'}] {
+				t.Fatalf("missing match of %s at %d", pi, x)
+			}
+		}
+	}
+	if n != len(got) {
+		t.Fatalf("%d matches, want %d", len(got), n)
+	}
+}
@@ -1,162 +1,155 @@
 package bio
 
-import (
-	"fmt"
-	"math"
-)
+import "math"
 
 // s1 is v in the text, indexed by i, and labeling down the left of the matrix
 // s2 is w in the text, indexed by j, and labeling across the top
 
-// WIP
+// AlignGlobalAffine computes a global alignment with affine gap penalties.
+//
+// It is AlignAffine with mode "global".
 func AlignGlobalAffine(s1, s2 Seq, a Aligner, gapOpenPenalty, gapExtendPenalty float64) (score float64, t1, t2 Seq) {
+	return AlignAffine("global", s1, s2, a, gapOpenPenalty, gapExtendPenalty)
+}
+
+// AlignAffine computes an alignment with affine gap penalties.
+//
+// Modes are those of AlignPair:  "global", "local", "fitting" where s1 is
+// fit within s2, and "overlap" where a suffix of s1 is aligned with a prefix
+// of s2.  A gap of length n is penalized gapOpenPenalty +
+// (n-1)*gapExtendPenalty.
+//
+// The algorithm is that of Gotoh, with three levels for a gap in s1,
+// a gap in s2, and the best score including match/mismatch.
+// For an invalid mode, score is -1 and t1, t2 are nil.
+func AlignAffine(mode string, s1, s2 Seq, a Aligner, gapOpenPenalty, gapExtendPenalty float64) (score float64, t1, t2 Seq) {
+	// skip prefixes of s1, s2, and anywhere for local
+	var skip1, skip2, local bool
+	switch mode {
+	case "global":
+	case "local":
+		skip1, skip2, local = true, true, true
+	case "fitting":
+		skip2 = true
+	case "overlap":
+		skip1 = true
+	default:
+		return -1, nil, nil
+	}
 	stride := len(s2) + 1
+	n := (len(s1) + 1) * stride
 	// score matrices
-	// sg1 holds scores for the state where gap in s1 is open
-	sg1 := make([]float64, (len(s1)+1)*stride) // "upper level" by the text
-	smm := make([]float64, (len(s1)+1)*stride) // "middle level"
-	sg2 := make([]float64, (len(s1)+1)*stride) // "lower level"
-	// values to store in b representing gap in s1, gap in s2, and
-	// match/mismatch respectively.  these can be subtracted from a
-	// position/node number to get the previous position/node number.
-	const g1 = 1
-	g2 := stride // g2, mm are also constant after set
-	mm := stride + 1
-	// backtrack info is a level-index pair
-	type bi struct {
-		level int // previous level. use g1, g2, mm values just defined
-		x     int // previous x.
-	}
-	// backtrack matrices.  parallel to s.
-	bg1 := make([]bi, len(sg1))
-	bg2 := make([]bi, len(sg1))
-	bmm := make([]bi, len(sg1))
-
-	// start of alignment has no gaps open, so -inf score for gapped states
-	sg1[0] = math.Inf(-1)
-	sg2[0] = math.Inf(-1)
-
-	// compute scores and backtracking for remaining nodes
-	var px int
-	var sMax float64
-	var bMax bi
-	for x := 1; x < len(sg1); x++ {
-		// compute sg1[x], "upper level"
-		if x%stride == 0 {
-			sg1[x] = math.Inf(-1) // sg1 -inf at "left edge"
+	sg1 := make([]float64, n) // "upper level", gap in s1
+	smm := make([]float64, n) // "middle level", best score
+	sg2 := make([]float64, n) // "lower level", gap in s2
+	// backtrack matrices.  values identify the previous level.
+	// affStart marks the start of an alignment.
+	bg1 := make([]byte, n)
+	bmm := make([]byte, n)
+	bg2 := make([]byte, n)
+	inf := math.Inf(-1)
+	sg1[0] = inf
+	sg2[0] = inf
+	bmm[0] = affStart
+	for x := 1; x < n; x++ {
+		i := x / stride
+		j := x % stride
+		// upper level, extend or open gap in s1
+		if j == 0 {
+			sg1[x] = inf
 		} else {
-			// compute sg1[x], bg1[x] as max of two cases:
-			px = x - g1
-			// case 1: extend g1
-			sMax = sg1[px] - gapExtendPenalty
-			bMax = bi{g1, px}
-			// case 2: open g1
-			if s0 := smm[px] - gapOpenPenalty; s0 > sMax {
-				sMax = s0
-				bMax.level = mm
+			sg1[x] = sg1[x-1] - gapExtendPenalty
+			bg1[x] = affG1
+			if s0 := smm[x-1] - gapOpenPenalty; s0 > sg1[x] {
+				sg1[x] = s0
+				bg1[x] = affMM
 			}
-			sg1[x] = sMax
-			bg1[x] = bMax
 		}
-
-		// compute sg2[x], "lower level"
-		if x < stride {
-			sg2[x] = math.Inf(-1)
+		// lower level, extend or open gap in s2
+		if i == 0 {
+			sg2[x] = inf
 		} else {
-			// compute sg2[x], bg2[x] as max of two cases:
-			px = x - g2
-			// case 1: extend g2
-			sMax = sg2[px] - gapExtendPenalty
-			bMax = bi{g2, px}
-			// case 2: open g2
-			if s0 := smm[px] - gapOpenPenalty; s0 > sMax {
-				sMax = s0
-				bMax.level = mm
+			sg2[x] = sg2[x-stride] - gapExtendPenalty
+			bg2[x] = affG2
+			if s0 := smm[x-stride] - gapOpenPenalty; s0 > sg2[x] {
+				sg2[x] = s0
+				bg2[x] = affMM
 			}
-			sg2[x] = sMax
-			bg2[x] = bMax
 		}
-
-		// compute smm[x], bmm[x] as max of three cases:
-		// case 1:  close gap in s2
-		sMax = sg2[x]
-		bMax = bi{g2, x}
-		// case 2: match/mismatch
-		i := x / stride
-		j := x % stride
-		if i > 0 && j > 0 {
-			px := x - mm
-			fmt.Println("x, i, j:", x, i, j)
-			s0 := smm[px] + float64(a.Score(s1[i-1], s2[j-1]))
-			if s0 > sMax {
-				sMax = s0
-				bMax = bi{mm, px}
-			}
+		// middle level
+		smm[x] = inf
+		bmm[x] = affStart
+		if local || i == 0 && skip2 || j == 0 && skip1 {
+			// start of alignment
+			smm[x] = 0
 		}
-		// case 3: close gap in s1
-		if s0 := sg1[x]; s0 > sMax {
-			sMax = s0
-			bMax = bi{g1, x}
+		// close gap in s2
+		if s0 := sg2[x]; s0 > smm[x] {
+			smm[x] = s0
+			bmm[x] = affG2
 		}
-		// store accumulated max of three cases
-		smm[x] = sMax
-		bmm[x] = bMax
-	}
-	fmt.Println("final scores")
-	for x := range sg1 {
-		fmt.Printf("(%.0f %.0f %.0f) ", sg1[x], smm[x], sg2[x])
-		if x%stride == stride-1 {
-			fmt.Println()
+		// match/mismatch
+		if i > 0 && j > 0 {
+			if s0 := smm[x-stride-1] + float64(a.Score(s1[i-1], s2[j-1])); s0 > smm[x] {
+				smm[x] = s0
+				bmm[x] = affMM
+			}
 		}
-	}
-	bl := map[int]string{g1: "g1", g2: "g2", mm: "mm"}
-	fmt.Println("final bg2")
-	for x, bx := range bg2 {
-		px := bx.x
-		fmt.Printf("(%s %d %d) ", bl[bx.level], px/stride, px%stride)
-		if x%stride == stride-1 {
-			fmt.Println()
+		// close gap in s1
+		if s0 := sg1[x]; s0 > smm[x] {
+			smm[x] = s0
+			bmm[x] = affG1
 		}
 	}
-	fmt.Println("final bmm")
-	for x, bx := range bmm {
-		px := bx.x
-		fmt.Printf("(%s %d %d) ", bl[bx.level], px/stride, px%stride)
-		if x%stride == stride-1 {
-			fmt.Println()
+	// end of alignment
+	xLast := n - 1
+	switch {
+	case local:
+		xLast = 0
+		for x, s := range smm {
+			if s > smm[xLast] {
+				xLast = x
+			}
 		}
-	}
-	fmt.Println("final bg1")
-	for x, bx := range bg1 {
-		px := bx.x
-		fmt.Printf("(%s %d %d) ", bl[bx.level], px/stride, px%stride)
-		if x%stride == stride-1 {
-			fmt.Println()
+	case mode == "fitting" || mode == "overlap":
+		// skip a suffix of s2.  best score across the bottom row.
+		xLast = n - stride
+		for x := xLast + 1; x < n; x++ {
+			if smm[x] > smm[xLast] {
+				xLast = x
+			}
 		}
 	}
-	fmt.Println("read out")
-	// read out from last position
-	x := len(sg1) - 1
-	score = smm[x]
-	fmt.Println("final score:", score)
-	b := bmm
-	// backtrack (from x at last position) then reverse
-	for ; x > 0; x = b[x].x {
-		switch b[x].level {
-		case g1:
+	score = smm[xLast]
+	// backtrack then reverse
+	x := xLast
+	level := byte(affMM)
+	for x > 0 {
+		switch level {
+		case affG1:
 			t1 = append(t1, GapSymbol)
 			t2 = append(t2, s2[x%stride-1])
-			b = bg1
-		case g2:
+			level = bg1[x]
+			x--
+			continue
+		case affG2:
 			t1 = append(t1, s1[x/stride-1])
 			t2 = append(t2, GapSymbol)
-			b = bg2
-		default:
+			level = bg2[x]
+			x -= stride
+			continue
+		}
+		// middle level
+		if bmm[x] == affStart {
+			break
+		}
+		if bmm[x] == affMM {
 			t1 = append(t1, s1[x/stride-1])
 			t2 = append(t2, s2[x%stride-1])
-			b = bmm
+			x -= stride + 1
+		} else {
+			level = bmm[x]
 		}
-		fmt.Println("x, t1, t2, b[x].x:", x, t1, t2, b[x].x)
 	}
 	last := len(t1) - 1
 	for i := range t1[:len(t1)/2] {
@@ -165,3 +158,11 @@ func AlignGlobalAffine(s1, s2 Seq, a Aligner, gapOpenPenalty, gapExtendPenalty f
 	}
 	return
 }
+
+// backtrack values for AlignAffine, identifying a previous level
+const (
+	affMM    = iota // match/mismatch, or the middle level
+	affG1           // gap in s1
+	affG2           // gap in s2
+	affStart        // start of alignment
+)
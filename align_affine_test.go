@@ -0,0 +1,157 @@
+package bio_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleAlignGlobalAffine() {
+	s1 := bio.Seq("PRTEINS")
+	s2 := bio.Seq("PRTWPSEIN")
+	score, t1, t2 := bio.AlignGlobalAffine(s1, s2, bio.Blosum62, 11, 1)
+	fmt.Println(score)
+	fmt.Println(t1)
+	fmt.Println(t2)
+	// Output:
+	// 8
+	// PRT---EINS
+	// PRTWPSEIN-
+}
+
+func ExampleAlignAffine_local() {
+	s1 := bio.Seq("PLEASANTLY")
+	s2 := bio.Seq("MEANLY")
+	score, t1, t2 := bio.AlignAffine("local", s1, s2, bio.Blosum62, 11, 1)
+	fmt.Println(score)
+	fmt.Println(t1)
+	fmt.Println(t2)
+	// Output:
+	// 12
+	// LEAS
+	// MEAN
+}
+
+func ExampleAlignAffine_fitting() {
+	s1 := bio.Seq("TAGATA")
+	s2 := bio.Seq("GTAGGCTTAAGGTTA")
+	score, t1, t2 := bio.AlignAffine("fitting", s1, s2, matchAligner{}, 2, 1)
+	fmt.Println(score)
+	fmt.Println(t1)
+	fmt.Println(t2)
+	// Output:
+	// 1
+	// TAG--ATA
+	// TAGGCTTA
+}
+
+func ExampleAlignAffine_overlap() {
+	s1 := bio.Seq("PAWHEAE")
+	s2 := bio.Seq("HEAGAWGHEE")
+	score, t1, t2 := bio.AlignAffine("overlap", s1, s2, bio.Blosum62, 11, 1)
+	fmt.Println(score)
+	fmt.Println(t1)
+	fmt.Println(t2)
+	// Output:
+	// 15
+	// HEAE
+	// HEAG
+}
+
+// With equal open and extend penalties, AlignAffine should score the same
+// as AlignPair.
+func TestAlignAffineLinear(t *testing.T) {
+	rs := func() bio.Seq {
+		s := make(bio.Seq, 1+rand.Intn(12))
+		for i := range s {
+			s[i] = "ACDEFGHIKL"[rand.Intn(10)]
+		}
+		return s
+	}
+	for _, mode := range []string{"global", "local", "fitting", "overlap"} {
+		for i := 0; i < 200; i++ {
+			s1, s2 := rs(), rs()
+			want, _, _ := bio.AlignPair(mode, s1, s2, bio.Blosum62, 3)
+			got, t1, t2 := bio.AlignAffine(mode, s1, s2, bio.Blosum62, 3, 3)
+			if got != float64(want) {
+				t.Fatalf("%s %s %s: score %g, AlignPair %d",
+					mode, s1, s2, got, want)
+			}
+			if len(t1) != len(t2) {
+				t.Fatalf("%s %s %s: traces %s %s", mode, s1, s2, t1, t2)
+			}
+			if g := bio.LinearGap(t1, t2, bio.Blosum62, 3); mode == "global" &&
+				float64(g) != got {
+				t.Fatalf("%s %s: trace score %d, score %g", s1, s2, g, got)
+			}
+		}
+	}
+}
+
+// affineScore scores aligned sequences with affine gap penalties.
+func affineScore(t1, t2 bio.Seq, a bio.Aligner, open, extend float64) (s float64) {
+	for i := range t1 {
+		switch {
+		case t1[i] == bio.GapSymbol:
+			if i > 0 && t1[i-1] == bio.GapSymbol {
+				s -= extend
+			} else {
+				s -= open
+			}
+		case t2[i] == bio.GapSymbol:
+			if i > 0 && t2[i-1] == bio.GapSymbol {
+				s -= extend
+			} else {
+				s -= open
+			}
+		default:
+			s += float64(a.Score(t1[i], t2[i]))
+		}
+	}
+	return
+}
+
+// TestAlignAffineTrace rescores traces with open and extend penalties
+// that differ and checks the traces against the sequences for each mode.
+func TestAlignAffineTrace(t *testing.T) {
+	rs := func() bio.Seq {
+		s := make(bio.Seq, rand.Intn(16))
+		for i := range s {
+			s[i] = "ACDEFGHIKL"[rand.Intn(10)]
+		}
+		return s
+	}
+	gap := []byte{bio.GapSymbol}
+	for _, mode := range []string{"global", "local", "fitting", "overlap"} {
+		for i := 0; i < 500; i++ {
+			s1, s2 := rs(), rs()
+			score, t1, t2 := bio.AlignAffine(mode, s1, s2, bio.Blosum62, 5, 1)
+			if len(t1) != len(t2) {
+				t.Fatalf("%s %s %s: traces %s %s", mode, s1, s2, t1, t2)
+			}
+			if g := affineScore(t1, t2, bio.Blosum62, 5, 1); g != score {
+				t.Fatalf("%s %s %s: trace %s %s scores %g, score %g",
+					mode, s1, s2, t1, t2, g, score)
+			}
+			u1 := bytes.Replace(t1, gap, nil, -1)
+			u2 := bytes.Replace(t2, gap, nil, -1)
+			var ok bool
+			switch mode {
+			case "global":
+				ok = bytes.Equal(u1, s1) && bytes.Equal(u2, s2)
+			case "local":
+				ok = bytes.Contains(s1, u1) && bytes.Contains(s2, u2)
+			case "fitting":
+				ok = bytes.Equal(u1, s1) && bytes.Contains(s2, u2)
+			case "overlap":
+				ok = bytes.HasSuffix(s1, u1) && bytes.HasPrefix(s2, u2)
+			}
+			if !ok {
+				t.Fatalf("%s %s %s: traces %s %s", mode, s1, s2, t1, t2)
+			}
+		}
+	}
+}
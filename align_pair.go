@@ -244,298 +244,3 @@ func (pa *pairAligner) align() {
 	}
 	pa.final()
 }
-
-/*
-func AlignAffine(mode string, s1, s2 Seq, a Aligner, gapOpenPenalty, gapExendPenalty float64) (score float64, t1, t2 Seq) {
-	aa := newAffineAligner(s1, s2, a, gapOpenPenalty, gapExendPenalty)
-	fmt.Println("AffineAlign len(s1), len(s2) =", len(s1), len(s2))
-	switch mode {
-	case "global":
-		aa.setGlobal()
-			case "local":
-				aa.setLocal()
-			case "fitting":
-				aa.setFitting()
-			case "overlap":
-				aa.setOverlap()
-	default:
-		return -1, nil, nil
-	}
-	aa.align()
-	score = aa.score
-	t1, t2 = aa.trace()
-	return
-}
-
-type affineAligner struct {
-	// parameters
-	s1, s2           Seq
-	a                Aligner
-	gapOpenPenalty   float64
-	gapExtendPenalty float64
-
-	// a couple values trivially derived from parameters but assigned to
-	// identifier names more meaningful in certain contexts.
-	stride int // = len(s2) + 1, stride for s, b.
-	mmx    int // x increment for match/mismatch, = stride + 1
-
-	// configuration for alignment mode
-	g1Rules []*aaRule
-	g2Rules []*aaRule
-	mmRules []*aaRule
-	final   func()
-
-	// main dynamic programming representation
-	// score matrix/nodes; for gap s1, gap s2, match/mismatch levels
-	sg1, sg2, smm []float64
-	bg1, bg2, bmm []abFunc // backtrack matrix.  parallel to s.
-
-	// results
-	xLast int     // node where alignment ends. used by trace
-	fLast abFunc  // bt func where alignment ends. used by trace
-	score float64 // score is final alignment score.
-}
-
-// back one step from x.
-// returns previous x, abFunc from previous level at previous x,
-// symbols to add to traces t1, t2
-type abFunc func(x int) (px int, pf abFunc, b1, b2 byte, pLev string)
-
-func newAffineAligner(s1, s2 Seq, a Aligner, gapOpenPenalty, gapExtendPenalty float64) *affineAligner {
-	aa := &affineAligner{
-		s1: s1, s2: s2, a: a,
-		gapOpenPenalty:   gapOpenPenalty,
-		gapExtendPenalty: gapExtendPenalty,
-	}
-	aa.sg1 = make([]float64, (len(s1)+1)*(len(s2)+1))
-	aa.sg2 = make([]float64, len(aa.sg1))
-	aa.smm = make([]float64, len(aa.sg1))
-	aa.bg1 = make([]abFunc, len(aa.sg1))
-	aa.bg2 = make([]abFunc, len(aa.sg1))
-	aa.bmm = make([]abFunc, len(aa.sg1))
-	aa.stride = len(s2) + 1
-	aa.mmx = aa.stride + 1 // match/mismatch increment
-	return aa
-}
-
-type aaRule struct {
-	score func(x int) float64
-	back  abFunc
-	name  string // for debugging :(
-}
-
-// open gap in s2
-func (aa *affineAligner) g2OpenScore(x int) float64 {
-	px := x - aa.stride
-	if px < 0 {
-		return math.Inf(-1)
-	}
-	return aa.smm[px] - aa.gapOpenPenalty
-}
-func (aa *affineAligner) g2OpenBack(x int) (int, abFunc, byte, byte, string) {
-	px := x - aa.stride
-	return px, aa.bmm[px], aa.s1[px/aa.stride], GapSymbol, "mm"
-}
-func (aa *affineAligner) g2OpenRule() *aaRule {
-	return &aaRule{aa.g2OpenScore, aa.g2OpenBack, "g2 open"}
-}
-
-// extend gap in s2
-func (aa *affineAligner) g2ExtendScore(x int) float64 {
-	px := x - aa.stride
-	if px < aa.stride {
-		return math.Inf(-1)
-	}
-	return aa.sg2[px] - aa.gapExtendPenalty
-}
-func (aa *affineAligner) g2ExtendBack(x int) (int, abFunc, byte, byte, string) {
-	px := x - aa.stride
-	return px, aa.bg2[px], aa.s1[px/aa.stride], GapSymbol, "g2"
-}
-func (aa *affineAligner) g2ExtendRule() *aaRule {
-	return &aaRule{aa.g2ExtendScore, aa.g2ExtendBack, "g2 extend"}
-}
-
-// close gap in s2
-func (aa *affineAligner) g2CloseScore(x int) float64 {
-	return aa.sg2[x]
-}
-func (aa *affineAligner) g2CloseBack(x int) (int, abFunc, byte, byte, string) {
-	return x, aa.bg2[x], 0, 0, "g2"
-}
-func (aa *affineAligner) g2CloseRule() *aaRule {
-	return &aaRule{aa.g2CloseScore, aa.g2CloseBack, "g2 close"}
-}
-
-// open gap in s1
-func (aa *affineAligner) g1OpenScore(x int) float64 {
-	if cx := x % aa.stride; cx == 0 {
-		return math.Inf(-1)
-	}
-	return aa.smm[x-1] - aa.gapOpenPenalty
-}
-func (aa *affineAligner) g1OpenBack(x int) (int, abFunc, byte, byte, string) {
-	px := x - 1
-	return px, aa.bmm[px], GapSymbol, aa.s2[px%aa.stride], "mm"
-}
-func (aa *affineAligner) g1OpenRule() *aaRule {
-	return &aaRule{aa.g1OpenScore, aa.g1OpenBack, "g1 open"}
-}
-
-// extend gap in s1
-func (aa *affineAligner) g1ExtendScore(x int) float64 {
-	if cx := x % aa.stride; cx <= 1 {
-		return math.Inf(-1)
-	}
-	return aa.sg1[x-1] - aa.gapExtendPenalty
-}
-func (aa *affineAligner) g1ExtendBack(x int) (int, abFunc, byte, byte, string) {
-	px := x - 1
-	return px, aa.bg1[px], GapSymbol, aa.s2[px%aa.stride], "g1"
-}
-func (aa *affineAligner) g1ExtendRule() *aaRule {
-	return &aaRule{aa.g1ExtendScore, aa.g1ExtendBack, "g1 extend"}
-}
-
-// close gap in s1
-func (aa *affineAligner) g1CloseScore(x int) float64 {
-	return aa.sg1[x]
-}
-func (aa *affineAligner) g1CloseBack(x int) (int, abFunc, byte, byte, string) {
-	return x, aa.bg1[x], 0, 0, "g1"
-}
-func (aa *affineAligner) g1CloseRule() *aaRule {
-	return &aaRule{aa.g1CloseScore, aa.g1CloseBack, "g1 close"}
-}
-
-// match/mismatch
-func (aa *affineAligner) mmScore(x int) float64 {
-	i := (x / aa.stride) - 1
-	j := (x % aa.stride) - 1
-	if i < 0 || j < 0 {
-		return math.Inf(-1)
-	}
-	fmt.Printf("      score %c %c = %d\n",
-		aa.s1[i], aa.s2[j], aa.a.Score(aa.s1[i], aa.s2[j]))
-	return aa.smm[x-aa.mmx] + float64(aa.a.Score(aa.s1[i], aa.s2[j]))
-}
-func (aa *affineAligner) mmBack(x int) (int, abFunc, byte, byte, string) {
-	px := x - aa.mmx
-	return px, aa.bmm[x], aa.s1[px/aa.stride], aa.s2[px%aa.stride], "mm"
-}
-func (aa *affineAligner) mmRule() *aaRule {
-	return &aaRule{aa.mmScore, aa.mmBack, "match/mismatch"}
-}
-
-func (aa *affineAligner) setGlobal() {
-	fmt.Println("setGlobal aa.stride", aa.stride)
-	aa.g1Rules = []*aaRule{
-		aa.g1ExtendRule(), // extend previous gap
-		aa.g1OpenRule(),   // open new one
-	}
-	aa.g2Rules = []*aaRule{
-		aa.g2ExtendRule(),
-		aa.g2OpenRule(),
-	}
-	aa.mmRules = []*aaRule{
-		aa.g1CloseRule(),
-		aa.mmRule(),
-		aa.g2CloseRule(),
-	}
-	// final result is level with max score in last position
-	aa.final = func() {
-		last := len(aa.sg1) - 1
-		sMax := aa.sg1[last]
-		fMax := aa.bg1[last]
-		if s := aa.smm[last]; s > sMax {
-			sMax = s
-			fMax = aa.bmm[last]
-		}
-		if s := aa.sg2[last]; s > sMax {
-			sMax = s
-			fMax = aa.bg2[last]
-		}
-		aa.score = sMax
-		aa.fLast = fMax
-		aa.xLast = last
-	}
-}
-
-func applyRules(rules []*aaRule, x int) (float64, abFunc) {
-	fmt.Println("apply rules node", x, len(rules), "rules")
-	sMax := rules[0].score(x)
-	bMax := rules[0].back
-	nMax := rules[0].name
-	fmt.Println("  ", nMax, "score", sMax)
-	for _, r := range rules[1:] {
-		s0 := r.score(x)
-		fmt.Println("  ", r.name, "score", s0)
-		if s0 > sMax {
-			sMax = s0
-			bMax = r.back
-			nMax = r.name
-		}
-	}
-	fmt.Println("max is", nMax, "score", sMax)
-	return sMax, bMax
-}
-
-func (aa *affineAligner) align() {
-	for x := 1; x < len(aa.sg1); x++ {
-		aa.sg1[x], aa.bg1[x] = applyRules(aa.g1Rules, x)
-		aa.sg2[x], aa.bg2[x] = applyRules(aa.g2Rules, x)
-		aa.smm[x], aa.bmm[x] = applyRules(aa.mmRules, x)
-	}
-	aa.final()
-	aa.dump()
-}
-
-func (aa *affineAligner) trace() (t1, t2 Seq) {
-	// backtrack then reverse
-	x := aa.xLast
-	f := aa.fLast
-	fmt.Println("trace! xLast, fLast", x, f)
-	var b1, b2 byte
-	for x > 0 {
-		fmt.Printf("x, f: %d %x ", x, f)
-		x, f, b1, b2, _ = f(x)
-		fmt.Printf("-> b1, b2: %c %c\n", b1, b2)
-		if b1 != 0 {
-			t1 = append(t1, b1)
-			t2 = append(t2, b2)
-		}
-	}
-	last := len(t1) - 1
-	for i := range t1[:len(t1)/2] {
-		t1[i], t1[last-i] = t1[last-i], t1[i]
-		t2[i], t2[last-i] = t2[last-i], t2[i]
-	}
-	return
-}
-
-func (aa *affineAligner) dump() {
-	fmt.Println("dump:")
-	fmt.Print("g1   ")
-	for _, b := range aa.s2 {
-		fmt.Print("  ", string(b))
-	}
-	for x, _ := range aa.sg1 {
-		// s1 symbol in left column
-		if x%aa.stride == 0 {
-			fmt.Println()
-			if x == 0 {
-				fmt.Print(" ")
-			} else {
-				i := x / aa.stride
-				fmt.Print(string(aa.s1[i-1]))
-			}
-		}
-		// row 1 of 3: score, bt dir.
-		pLev := "--"
-		if bf := aa.bg1[x]; bf != nil {
-			_, _, _, _, pLev = bf(x)
-		}
-		fmt.Printf("%6.0f %s", aa.sg1[x], pLev)
-	}
-}
-*/
@@ -0,0 +1,233 @@
+package bio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BEDRecord is a single line of a BED file.
+//
+// Coordinates of BED are 0-based and half-open and are used unchanged.
+// Fields is the number of columns present, from 3 to 12.  Fields beyond
+// the number of columns are zero values, except Strand which is '.'.
+// For BED12, Blocks holds the blocks as absolute coordinates.
+type BEDRecord struct {
+	Chrom      string
+	Start, End int
+	Name       string
+	Score      int
+	Strand     byte // '+', '-', or '.'
+	ThickStart int
+	ThickEnd   int
+	ItemRGB    string
+	Blocks     []Interval
+	Fields     int
+}
+
+// Interval returns the coordinates of the record.
+func (b BEDRecord) Interval() Interval {
+	return Interval{b.Start, b.End}
+}
+
+// regions returns the blocks if present, or else the whole interval.
+func (b BEDRecord) regions() []Interval {
+	if len(b.Blocks) > 0 {
+		return b.Blocks
+	}
+	return []Interval{b.Interval()}
+}
+
+// Extract returns the sequence of the record from a reference sequence.
+//
+// The ID of s must match b.Chrom.  If the record has blocks, the sequences
+// of the blocks are concatenated.  The result is reverse complemented for
+// records on the '-' strand.
+func (b BEDRecord) Extract(s FASTASeq) (DNA8, error) {
+	return extract(b.regions(), b.Strand, fastaRegion(s, b.Chrom))
+}
+
+// ExtractIndexed returns the sequence of the record from an indexed
+// reference.
+//
+// Blocks and strand are handled as for Extract.
+func (b BEDRecord) ExtractIndexed(x *IndexedFASTA) (DNA8, error) {
+	return extract(b.regions(), b.Strand, indexedRegion(x, b.Chrom))
+}
+
+// BEDReader reads records from a BED stream.
+type BEDReader struct {
+	r    *bufio.Reader
+	line int // number of lines read
+}
+
+// NewBEDReader constructs a BEDReader around an io.Reader.
+func NewBEDReader(r io.Reader) BEDReader {
+	return BEDReader{r: bufio.NewReader(r)}
+}
+
+func (r *BEDReader) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("BED line %d: "+format,
+		append([]interface{}{r.line}, a...)...)
+}
+
+// ReadRecord returns a single record on each call.
+//
+// Columns may be separated by tabs or spaces.  Blank lines, comments, and
+// track and browser lines are skipped.  A successful read is indicated
+// by err = nil for all records, including the last.  Subsequent calls
+// return err = io.EOF.
+func (r *BEDReader) ReadRecord() (b BEDRecord, err error) {
+	var c []string
+	for {
+		l, err := readLine(r.r)
+		if err != nil {
+			return b, err
+		}
+		r.line++
+		line := string(l)
+		if line == "" || line[0] == '#' ||
+			strings.HasPrefix(line, "track") ||
+			strings.HasPrefix(line, "browser") {
+			continue
+		}
+		if strings.IndexByte(line, '\t') >= 0 {
+			c = strings.Split(line, "\t")
+		} else {
+			c = strings.Fields(line)
+		}
+		break
+	}
+	if len(c) < 3 || len(c) > 12 {
+		return b, r.errorf("%d columns", len(c))
+	}
+	b.Fields = len(c)
+	b.Chrom = c[0]
+	b.Strand = '.'
+	ints := []*int{&b.Start, &b.End, nil, &b.Score, nil, &b.ThickStart, &b.ThickEnd}
+	for i, p := range ints {
+		if p == nil || i+1 >= len(c) {
+			continue
+		}
+		if *p, err = strconv.Atoi(c[i+1]); err != nil {
+			return b, r.errorf("invalid column %d: %s", i+2, c[i+1])
+		}
+	}
+	if b.Start < 0 || b.End < b.Start {
+		return b, r.errorf("invalid range %s:%s", c[1], c[2])
+	}
+	if len(c) > 3 {
+		b.Name = c[3]
+	}
+	if len(c) > 5 {
+		if len(c[5]) != 1 || strings.IndexByte("+-.", c[5][0]) < 0 {
+			return b, r.errorf("invalid strand %s", c[5])
+		}
+		b.Strand = c[5][0]
+	}
+	if len(c) > 8 {
+		b.ItemRGB = c[8]
+	}
+	if len(c) > 9 {
+		if len(c) != 12 {
+			return b, r.errorf("incomplete block columns")
+		}
+		if b.Blocks, err = bedBlocks(b.Start, c[9], c[10], c[11]); err != nil {
+			return b, r.errorf("%v", err)
+		}
+	}
+	return b, nil
+}
+
+// bedBlocks parses the block columns of BED12.
+func bedBlocks(start int, count, sizes, starts string) ([]Interval, error) {
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block count %s", count)
+	}
+	sz := strings.Split(strings.TrimSuffix(sizes, ","), ",")
+	st := strings.Split(strings.TrimSuffix(starts, ","), ",")
+	if len(sz) != n || len(st) != n {
+		return nil, fmt.Errorf("%d block sizes and %d starts for %d blocks",
+			len(sz), len(st), n)
+	}
+	bl := make([]Interval, n)
+	for i := range bl {
+		s, err1 := strconv.Atoi(st[i])
+		z, err2 := strconv.Atoi(sz[i])
+		if err1 != nil || err2 != nil || s < 0 || z < 0 {
+			return nil, fmt.Errorf("invalid block %s,%s", st[i], sz[i])
+		}
+		bl[i] = Interval{start + s, start + s + z}
+	}
+	return bl, nil
+}
+
+// ReadBED reads all records from a BED stream.
+func ReadBED(r io.Reader) (b []BEDRecord, err error) {
+	br := NewBEDReader(r)
+	for {
+		rec, err := br.ReadRecord()
+		switch {
+		case err == io.EOF:
+			return b, nil
+		case err != nil:
+			return nil, err
+		}
+		b = append(b, rec)
+	}
+}
+
+// BEDWriter writes BED.
+//
+// Output is buffered.  Call Flush after the last record is written.
+type BEDWriter struct {
+	w *bufio.Writer
+}
+
+// NewBEDWriter constructs a BEDWriter around an io.Writer.
+func NewBEDWriter(w io.Writer) *BEDWriter {
+	return &BEDWriter{bufio.NewWriter(w)}
+}
+
+// WriteRecord writes a single record as a tab separated line.
+//
+// The number of columns written is b.Fields.  If b.Fields is 0,
+// the number is chosen as 12 if b has blocks, otherwise 6.
+func (w *BEDWriter) WriteRecord(b BEDRecord) error {
+	n := b.Fields
+	if n == 0 {
+		n = 6
+		if len(b.Blocks) > 0 {
+			n = 12
+		}
+	}
+	if n < 3 || n > 12 || n > 9 && n < 12 {
+		return fmt.Errorf("BED: can't write %d columns", n)
+	}
+	strand := b.Strand
+	if strand == 0 {
+		strand = '.'
+	}
+	c := []string{b.Chrom, strconv.Itoa(b.Start), strconv.Itoa(b.End),
+		b.Name, strconv.Itoa(b.Score), string(strand),
+		strconv.Itoa(b.ThickStart), strconv.Itoa(b.ThickEnd), b.ItemRGB}
+	if n == 12 {
+		var sz, st []string
+		for _, bl := range b.Blocks {
+			sz = append(sz, strconv.Itoa(bl.End-bl.Start))
+			st = append(st, strconv.Itoa(bl.Start-b.Start))
+		}
+		c = append(c, strconv.Itoa(len(b.Blocks)),
+			strings.Join(sz, ","), strings.Join(st, ","))
+	}
+	w.w.WriteString(strings.Join(c[:n], "\t"))
+	return w.w.WriteByte('\n')
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *BEDWriter) Flush() error {
+	return w.w.Flush()
+}
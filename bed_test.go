@@ -0,0 +1,54 @@
+package bio_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/soniakeys/bio"
+)
+
+const bedTestData = `track name=test
+chr1	2	20	gene1	0	+
+chr1	20	26	gene2	0	-
+chr1	2	26	tx1	0	+	2	26	0	2	9,6,	0,18,
+`
+
+func ExampleReadBED() {
+	b, err := bio.ReadBED(strings.NewReader(bedTestData))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, r := range b {
+		fmt.Println(r.Chrom, r.Start, r.End, r.Name, string(r.Strand),
+			r.Blocks, r.Fields)
+	}
+	w := bio.NewBEDWriter(os.Stdout)
+	w.WriteRecord(b[2])
+	w.Flush()
+	// Output:
+	// chr1 2 20 gene1 + [] 6
+	// chr1 20 26 gene2 - [] 6
+	// chr1 2 26 tx1 + [{2 11} {20 26}] 12
+	// chr1	2	26	tx1	0	+	2	26	0	2	9,6	0,18
+}
+
+func ExampleBEDRecord_ExtractIndexed() {
+	fa := ">chr1\nccATGAAACGTTTTTG\nGGGGTCATTTTTTT\n"
+	idx, err := bio.BuildFAI(strings.NewReader(fa))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	x := bio.NewIndexedFASTA(bytes.NewReader([]byte(fa)), idx)
+	b, _ := bio.ReadBED(strings.NewReader(bedTestData))
+	for _, r := range b {
+		fmt.Println(r.ExtractIndexed(x))
+	}
+	// Output:
+	// ATGAAACGTTTTTGGGGG <nil>
+	// AAATGA <nil>
+	// ATGAAACGTTCATTT <nil>
+}
@@ -0,0 +1,362 @@
+package bio
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+)
+
+// OpenSeqFile opens a file for reading, decompressing it if it is gzipped.
+//
+// Compression is detected by the gzip magic number rather than by the file
+// name.  Concatenated gzip members, as in BGZF files, are read as a single
+// stream.  The caller should Close the result.
+func OpenSeqFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		// not gzip.  (this includes files too short to be gzip.)
+		return &seqFile{br, f}, nil
+	}
+	z, err := gzip.NewReader(br)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &seqFile{z, f}, nil
+}
+
+// seqFile reads from a possibly decompressing reader and closes the
+// underlying file.
+type seqFile struct {
+	io.Reader
+	f *os.File
+}
+
+func (s *seqFile) Close() error {
+	if z, ok := s.Reader.(*gzip.Reader); ok {
+		z.Close()
+	}
+	return s.f.Close()
+}
+
+// BGZFOffset is a BGZF virtual file offset.
+//
+// The upper 48 bits are the file offset of a BGZF block.  The lower 16 bits
+// are an offset into the decompressed data of the block.
+type BGZFOffset uint64
+
+// MakeBGZFOffset constructs a BGZFOffset from a block offset and an offset
+// within the block.
+func MakeBGZFOffset(block int64, within int) BGZFOffset {
+	return BGZFOffset(block<<16 | int64(within))
+}
+
+// Block returns the file offset of the BGZF block.
+func (v BGZFOffset) Block() int64 { return int64(v >> 16) }
+
+// Within returns the offset into the decompressed data of the block.
+func (v BGZFOffset) Within() int { return int(v & 0xffff) }
+
+// bgzfBlock locates a BGZF block both in the file and in the uncompressed
+// data.
+type bgzfBlock struct {
+	coff  int64 // file offset of block
+	csize int   // size of block in file
+	uoff  int64 // offset of block data in uncompressed stream
+	usize int   // size of uncompressed block data
+}
+
+// BGZFReader reads BGZF, the blocked gzip format written by bgzip.
+//
+// BGZFReader supports sequential reading through Read, seeking to virtual
+// offsets with Seek, and random access by uncompressed offset through
+// ReadAt.  ReadAt allows an IndexedFASTA to work directly on a bgzipped
+// FASTA file, using a FASTA index of the uncompressed data.
+type BGZFReader struct {
+	r      io.ReaderAt
+	blocks []bgzfBlock
+	// state for sequential reading
+	cur int    // index of current block
+	buf []byte // decompressed data of current block
+	pos int    // read position within buf
+}
+
+// NewBGZFReader constructs a BGZFReader.
+//
+// It scans the block headers of the entire file to construct an index of
+// the blocks but does not decompress any data.
+func NewBGZFReader(r io.ReaderAt) (*BGZFReader, error) {
+	z := &BGZFReader{r: r, cur: -1}
+	var coff, uoff int64
+	var h [18]byte
+	for {
+		n, err := r.ReadAt(h[:], coff)
+		if n == 0 && err == io.EOF {
+			break
+		}
+		if n < len(h) {
+			return nil, fmt.Errorf("BGZF: truncated block at offset %d", coff)
+		}
+		if h[0] != 0x1f || h[1] != 0x8b || h[2] != 8 || h[3]&4 == 0 {
+			return nil, fmt.Errorf("BGZF: invalid block header at offset %d", coff)
+		}
+		xlen := int(binary.LittleEndian.Uint16(h[10:]))
+		bsize, err := z.bsize(coff, xlen)
+		if err != nil {
+			return nil, err
+		}
+		var isize [4]byte
+		if _, err := r.ReadAt(isize[:], coff+int64(bsize)-4); err != nil {
+			return nil, fmt.Errorf("BGZF: truncated block at offset %d", coff)
+		}
+		usize := int(binary.LittleEndian.Uint32(isize[:]))
+		z.blocks = append(z.blocks, bgzfBlock{coff, bsize, uoff, usize})
+		coff += int64(bsize)
+		uoff += int64(usize)
+	}
+	return z, nil
+}
+
+// bsize finds the BC subfield of the gzip extra field and returns the
+// total block size.
+func (z *BGZFReader) bsize(coff int64, xlen int) (int, error) {
+	x := make([]byte, xlen)
+	if _, err := z.r.ReadAt(x, coff+12); err != nil {
+		return 0, fmt.Errorf("BGZF: truncated block at offset %d", coff)
+	}
+	for len(x) >= 4 {
+		slen := int(binary.LittleEndian.Uint16(x[2:]))
+		if x[0] == 'B' && x[1] == 'C' && slen == 2 && len(x) >= 6 {
+			return int(binary.LittleEndian.Uint16(x[4:])) + 1, nil
+		}
+		if len(x) < 4+slen {
+			break
+		}
+		x = x[4+slen:]
+	}
+	return 0, fmt.Errorf("BGZF: no BC subfield in block at offset %d", coff)
+}
+
+// Size returns the total size of the uncompressed data.
+func (z *BGZFReader) Size() int64 {
+	if len(z.blocks) == 0 {
+		return 0
+	}
+	b := z.blocks[len(z.blocks)-1]
+	return b.uoff + int64(b.usize)
+}
+
+// inflate decompresses block b into buf, returning the data.
+func (z *BGZFReader) inflate(b bgzfBlock, buf []byte) ([]byte, error) {
+	c := make([]byte, b.csize)
+	if _, err := z.r.ReadAt(c, b.coff); err != nil {
+		return nil, err
+	}
+	xlen := int(binary.LittleEndian.Uint16(c[10:]))
+	fr := flate.NewReader(bytes.NewReader(c[12+xlen : b.csize-8]))
+	defer fr.Close()
+	if cap(buf) < b.usize {
+		buf = make([]byte, b.usize)
+	}
+	buf = buf[:b.usize]
+	if _, err := io.ReadFull(fr, buf); err != nil {
+		return nil, fmt.Errorf("BGZF: block at offset %d: %v", b.coff, err)
+	}
+	if crc32.ChecksumIEEE(buf) != binary.LittleEndian.Uint32(c[b.csize-8:]) {
+		return nil, fmt.Errorf("BGZF: checksum error in block at offset %d",
+			b.coff)
+	}
+	return buf, nil
+}
+
+// load makes block i the current block.
+func (z *BGZFReader) load(i int) (err error) {
+	z.buf, err = z.inflate(z.blocks[i], z.buf)
+	if err != nil {
+		return err
+	}
+	z.cur = i
+	z.pos = 0
+	return nil
+}
+
+// Read reads decompressed data sequentially, satisfying io.Reader.
+func (z *BGZFReader) Read(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		if z.cur < 0 || z.pos == len(z.buf) {
+			if z.cur+1 >= len(z.blocks) {
+				if n == 0 {
+					err = io.EOF
+				}
+				return
+			}
+			if err = z.load(z.cur + 1); err != nil {
+				return
+			}
+			continue
+		}
+		c := copy(p, z.buf[z.pos:])
+		z.pos += c
+		n += c
+		p = p[c:]
+	}
+	return
+}
+
+// Seek positions the reader at a virtual offset, as found in indexes
+// of BGZF files.
+func (z *BGZFReader) Seek(v BGZFOffset) error {
+	i := sort.Search(len(z.blocks), func(i int) bool {
+		return z.blocks[i].coff >= v.Block()
+	})
+	if i == len(z.blocks) || z.blocks[i].coff != v.Block() {
+		return fmt.Errorf("BGZF: no block at offset %d", v.Block())
+	}
+	if v.Within() > z.blocks[i].usize {
+		return errors.New("BGZF: virtual offset beyond end of block")
+	}
+	if z.cur != i {
+		if err := z.load(i); err != nil {
+			return err
+		}
+	}
+	z.pos = v.Within()
+	return nil
+}
+
+// Tell returns the virtual offset of the sequential read position.
+func (z *BGZFReader) Tell() BGZFOffset {
+	if z.cur < 0 {
+		return 0
+	}
+	if z.pos == len(z.buf) && z.cur+1 < len(z.blocks) {
+		// by convention, point to the start of the next block
+		return MakeBGZFOffset(z.blocks[z.cur+1].coff, 0)
+	}
+	return MakeBGZFOffset(z.blocks[z.cur].coff, z.pos)
+}
+
+// ReadAt reads decompressed data starting at offset off of the uncompressed
+// stream, satisfying io.ReaderAt.
+//
+// ReadAt does not use or affect the sequential read position.
+func (z *BGZFReader) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New("BGZF: negative offset")
+	}
+	i := sort.Search(len(z.blocks), func(i int) bool {
+		b := z.blocks[i]
+		return b.uoff+int64(b.usize) > off
+	})
+	var buf []byte
+	for ; len(p) > 0 && i < len(z.blocks); i++ {
+		b := z.blocks[i]
+		if b.usize == 0 {
+			continue
+		}
+		if buf, err = z.inflate(b, buf); err != nil {
+			return
+		}
+		c := copy(p, buf[off-b.uoff:])
+		n += c
+		off += int64(c)
+		p = p[c:]
+	}
+	if len(p) > 0 {
+		err = io.EOF
+	}
+	return
+}
+
+// bgzfMaxData is the amount of data written per block by BGZFWriter,
+// matching bgzip.
+const bgzfMaxData = 0xff00
+
+// bgzfEOF is the standard empty block marking the end of a BGZF file.
+var bgzfEOF = []byte{
+	0x1f, 0x8b, 8, 4, 0, 0, 0, 0, 0, 0xff, 6, 0, 'B', 'C', 2, 0,
+	0x1b, 0, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+}
+
+// BGZFWriter writes BGZF, the blocked gzip format of bgzip.
+//
+// Close must be called to write the final block and the end of file marker.
+// Close does not close the underlying io.Writer.
+type BGZFWriter struct {
+	w   io.Writer
+	buf []byte // data not yet written
+	c   bytes.Buffer
+	fw  *flate.Writer
+}
+
+// NewBGZFWriter constructs a BGZFWriter around an io.Writer.
+func NewBGZFWriter(w io.Writer) *BGZFWriter {
+	return &BGZFWriter{w: w}
+}
+
+// Write satisfies io.Writer.
+func (z *BGZFWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		c := bgzfMaxData - len(z.buf)
+		if c > len(p) {
+			c = len(p)
+		}
+		z.buf = append(z.buf, p[:c]...)
+		n += c
+		p = p[c:]
+		if len(z.buf) == bgzfMaxData {
+			if err = z.flushBlock(); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// flushBlock writes buffered data as a single block.
+func (z *BGZFWriter) flushBlock() (err error) {
+	z.c.Reset()
+	if z.fw == nil {
+		z.fw, _ = flate.NewWriter(&z.c, flate.DefaultCompression)
+	} else {
+		z.fw.Reset(&z.c)
+	}
+	z.fw.Write(z.buf)
+	z.fw.Close()
+	h := []byte{0x1f, 0x8b, 8, 4, 0, 0, 0, 0, 0, 0xff, 6, 0, 'B', 'C', 2, 0, 0, 0}
+	binary.LittleEndian.PutUint16(h[16:], uint16(len(h)+z.c.Len()+8-1))
+	var t [8]byte
+	binary.LittleEndian.PutUint32(t[:], crc32.ChecksumIEEE(z.buf))
+	binary.LittleEndian.PutUint32(t[4:], uint32(len(z.buf)))
+	if _, err = z.w.Write(h); err == nil {
+		if _, err = z.w.Write(z.c.Bytes()); err == nil {
+			_, err = z.w.Write(t[:])
+		}
+	}
+	z.buf = z.buf[:0]
+	return
+}
+
+// Close writes any buffered data and the end of file marker.
+func (z *BGZFWriter) Close() error {
+	if len(z.buf) > 0 {
+		if err := z.flushBlock(); err != nil {
+			return err
+		}
+	}
+	_, err := z.w.Write(bgzfEOF)
+	return err
+}
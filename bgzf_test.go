@@ -0,0 +1,142 @@
+package bio_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+// bgzfTestData returns a two sequence FASTA file of about 150KB, enough
+// for several BGZF blocks.
+func bgzfTestData() []byte {
+	var b bytes.Buffer
+	fw := bio.NewFASTAWriter(&b)
+	fw.WriteRecord("s1", "", bytes.Repeat([]byte("ACGTTGCA"), 12000))
+	fw.WriteRecord("s2", "", bytes.Repeat([]byte("GGGGCCCCAATT"), 5000))
+	fw.Flush()
+	return b.Bytes()
+}
+
+func ExampleBGZFReader() {
+	// compress
+	d := bgzfTestData()
+	var c bytes.Buffer
+	w := bio.NewBGZFWriter(&c)
+	w.Write(d)
+	w.Close()
+	// index uncompressed data read sequentially from the BGZF reader
+	z, err := bio.NewBGZFReader(bytes.NewReader(c.Bytes()))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	idx, err := bio.BuildFAI(z)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(z.Size() == int64(len(d)))
+	// random access to the compressed file
+	x := bio.NewIndexedFASTA(z, idx)
+	fmt.Println(x.Seq("s1", 95990, 96000))
+	fmt.Println(x.Seq("s2", 29998, 30010))
+	// Output:
+	// true
+	// CAACGTTGCA <nil>
+	// TTGGGGCCCCAA <nil>
+}
+
+func TestBGZFSeek(t *testing.T) {
+	d := bgzfTestData()
+	var c bytes.Buffer
+	w := bio.NewBGZFWriter(&c)
+	w.Write(d)
+	w.Close()
+	z, err := bio.NewBGZFReader(bytes.NewReader(c.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// read into the second block, remember the position, read on
+	first := make([]byte, 70000)
+	if _, err := io.ReadFull(z, first); err != nil {
+		t.Fatal(err)
+	}
+	v := z.Tell()
+	if v.Block() == 0 || v.Within() != 70000-0xff00 {
+		t.Fatalf("Tell = %d, %d", v.Block(), v.Within())
+	}
+	rest, err := ioutil.ReadAll(z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(append(first, rest...), d) {
+		t.Fatal("sequential read mismatch")
+	}
+	if err := z.Seek(v); err != nil {
+		t.Fatal(err)
+	}
+	again := make([]byte, 100)
+	if _, err := io.ReadFull(z, again); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, rest[:100]) {
+		t.Fatal("read after seek mismatch")
+	}
+	if _, err := z.ReadAt(again, -1); err == nil {
+		t.Fatal("ReadAt negative offset: no error")
+	}
+	if _, err := z.ReadAt(again, 69950); err != nil ||
+		!bytes.Equal(again, d[69950:70050]) {
+		t.Fatal("ReadAt mismatch", err)
+	}
+}
+
+func TestReadFASTAFileGzip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	d := ">s1\nACGT\n>s2\nTTGG\n"
+	// plain gzip
+	p := filepath.Join(dir, "a.fa.gz")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte(d))
+	gz.Close()
+	f.Close()
+	// bgzip
+	q := filepath.Join(dir, "b.fa.bgz")
+	if f, err = os.Create(q); err != nil {
+		t.Fatal(err)
+	}
+	bz := bio.NewBGZFWriter(f)
+	io.Copy(bz, strings.NewReader(d))
+	bz.Close()
+	f.Close()
+	// uncompressed
+	u := filepath.Join(dir, "c.fa")
+	if err = ioutil.WriteFile(u, []byte(d), 0666); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{p, q, u} {
+		s, err := bio.ReadFASTAFile(path)
+		if err != nil {
+			t.Fatal(path, err)
+		}
+		if len(s) != 2 || string(s[1].Seq) != "TTGG" {
+			t.Fatal(path, "wrong content")
+		}
+	}
+}
@@ -1,17 +1,30 @@
 package bio
 
-import "sort"
+import (
+	"encoding/binary"
+	"sort"
+)
 
 // BWT is a searchable Burrows-Wheeler transform of a string.
 type BWT struct {
 	bwt []byte
-	psa map[int]int // partial suffix array
-	fo  [256]int    // first occurrence
-	cx  [256]int    // index from byte to counts
-	cp  [][]int     // checkpoints
-	mod int         // sparseness of checkpoint array
+	psa u64s     // partial suffix array, pairs of row, value, by row
+	fo  [256]int // first occurrence
+	cx  [256]int // index from byte to counts
+	cp  u64s     // checkpoints, len(sx) counts each
+	mod int      // sparseness of checkpoint array
+	sx  []byte   // unique symbols in lexical order, sentinal first
 }
 
+// u64s holds little-endian uint64 values.  It is the representation of
+// the partial suffix array and checkpoints both in memory and in
+// serialized data, so that a loaded BWT can share memory with the data.
+type u64s []byte
+
+func (v u64s) at(i int) int     { return int(binary.LittleEndian.Uint64(v[8*i:])) }
+func (v u64s) put(i int, x int) { binary.LittleEndian.PutUint64(v[8*i:], uint64(x)) }
+func (v u64s) len() int         { return len(v) / 8 }
+
 // NewBWT constructs a Burrows-Wheeler transform of a string.
 //
 // The string is interpreted byte-wise, not as runes.
@@ -21,57 +34,76 @@ type BWT struct {
 // If missing, it will be added to an internal copy of the string (increasing
 // memory requirements.)  The last parameter, mod, is a tunable parameter for
 // reducing memory requirements.  For long strings try a value of 100.
+//
+// Construction uses SuffixArray and takes time linear in the length of s.
+// Memory during construction is dominated by the suffix array, an int for
+// each byte of s.  Afterward the transform takes a byte for each byte of
+// s plus the partial suffix array and checkpoints, which shrink as mod
+// grows.
 func NewBWT(s string, sentinal byte, mod int) *BWT {
 	if s[len(s)-1] != sentinal {
 		s += string(sentinal)
 	}
+	return newBWT(s, mod, nil)
+}
+
+// newBWT constructs the BWT of s, which must end with the sentinal.
+// The partial suffix array holds positions that are multiples of mod and
+// positions in extra.
+func newBWT(s string, mod int, extra map[int]bool) *BWT {
 	bx := &BWT{
 		bwt: make([]byte, len(s)),
-		psa: map[int]int{},
-		cp:  make([][]int, len(s)/mod+1),
+		psa: make(u64s, 0, 16*(len(s)/mod+len(extra)+1)),
 		mod: mod,
 	}
 	// construct bwt, psa
-	ss := make([]string, len(s))
-	for i := range s {
-		ss[i] = s[i:]
-	}
-	sort.Strings(ss)
-	for i, si := range ss {
-		sa := len(s) - len(si) // suffix array value
-		if sa%mod == 0 {
-			bx.psa[i] = sa // only store partial suffix array
+	var e [16]byte
+	for i, sa := range SuffixArray([]byte(s)) {
+		if sa%mod == 0 || extra[sa] {
+			// only store partial suffix array
+			u64s(e[:]).put(0, i)
+			u64s(e[:]).put(1, sa)
+			bx.psa = append(bx.psa, e[:]...)
 		}
 		bx.bwt[i] = s[(sa+len(s)-1)%len(s)]
 	}
-
-	lex := append(bwb{}, bx.bwt...)
-	sort.Sort(lex)
-	sx := []byte{} // unique symbols
-	for i := range lex {
-		b := lex[i]
-		if bx.cx[b] == 0 {
-			bx.fo[b] = i
-			bx.cx[b] = len(sx)
-			sx = append(sx, b)
+	// symbol counts give first occurrences in the sorted bwt
+	var n [256]int
+	for _, b := range bx.bwt {
+		n[b]++
+	}
+	f := 0
+	for b, c := range n {
+		if c > 0 {
+			bx.fo[b] = f
+			bx.cx[b] = len(bx.sx)
+			bx.sx = append(bx.sx, byte(b))
+			f += c
+		}
+	}
+	ns := len(bx.sx)
+	bx.cp = make(u64s, 8*(len(s)/mod+1)*ns)
+	counts := make([]int, ns)
+	checkpoint := func(m int) {
+		for k, c := range counts {
+			bx.cp.put(m*ns+k, c)
 		}
 	}
-	counts := make([]int, len(sx))
 	for i, b := range bx.bwt {
 		if i%mod == 0 {
-			bx.cp[i/mod] = append([]int{}, counts...)
+			checkpoint(i / mod)
 		}
 		counts[bx.cx[b]]++
 	}
 	if len(bx.bwt)%mod == 0 {
-		bx.cp[len(bx.cp)-1] = counts
+		checkpoint(len(bx.bwt) / mod)
 	}
 	return bx
 }
 
 func (b *BWT) count(sym byte, i int) int {
 	m := i / b.mod
-	c := b.cp[m][b.cx[sym]]
+	c := b.cp.at(m*len(b.sx) + b.cx[sym])
 	for j := m * b.mod; j < i; j++ {
 		if b.bwt[j] == sym {
 			c++
@@ -80,6 +112,43 @@ func (b *BWT) count(sym byte, i int) int {
 	return c
 }
 
+// has returns true if sym is present in the transform.
+func (b *BWT) has(sym byte) bool {
+	return b.cx[sym] > 0 || sym == b.sx[0]
+}
+
+// extend narrows the range top..bot of rows matching some suffix of
+// a pattern to the rows matching sym followed by that suffix.
+// The result is an empty range, with top > bot, if there are none.
+func (b *BWT) extend(sym byte, top, bot int) (int, int) {
+	if !b.has(sym) {
+		return 1, 0
+	}
+	return b.fo[sym] + b.count(sym, top), b.fo[sym] + b.count(sym, bot+1) - 1
+}
+
+// sampled returns the suffix array value of row j if j is in the partial
+// suffix array.
+func (b *BWT) sampled(j int) (int, bool) {
+	np := b.psa.len() / 2
+	x := sort.Search(np, func(x int) bool { return b.psa.at(2*x) >= j })
+	if x < np && b.psa.at(2*x) == j {
+		return b.psa.at(2*x + 1), true
+	}
+	return 0, false
+}
+
+// locate returns the position in the string of the suffix at row j.
+func (b *BWT) locate(j int) int {
+	for k := 0; ; k++ {
+		if m, ok := b.sampled(j); ok {
+			return m + k
+		}
+		sym := b.bwt[j]
+		j = b.fo[sym] + b.count(sym, j)
+	}
+}
+
 // AllIndex returns all indexes of pat in the string indexed by BWT.
 func (b *BWT) AllIndex(pat string) []int {
 	top := 0
@@ -88,28 +157,88 @@ func (b *BWT) AllIndex(pat string) []int {
 		if last < 0 {
 			p := make([]int, bot+1-top)
 			for i := range p {
-				j := top + i
-				for k := 0; ; k++ {
-					m, ok := b.psa[j]
-					if ok {
-						p[i] = m + k
-						break
-					}
-					sym := b.bwt[j]
-					j = b.fo[sym] + b.count(sym, j)
-				}
+				p[i] = b.locate(top + i)
 			}
 			return p
 		}
-		sym := pat[last]
-		top = b.fo[sym] + b.count(sym, top)
-		bot = b.fo[sym] + b.count(sym, bot+1) - 1
+		top, bot = b.extend(pat[last], top, bot)
 	}
 	return nil
 }
 
-type bwb []byte
+// Inverse recovers the string from which the BWT was constructed.
+//
+// The result does not include the sentinal.
+func (b *BWT) Inverse() string {
+	n := len(b.bwt)
+	s := make([]byte, n-1)
+	// row 0 is the suffix holding only the sentinal.  walk LF mapping
+	// backward from there.
+	j := 0
+	for i := n - 2; i >= 0; i-- {
+		sym := b.bwt[j]
+		s[i] = sym
+		j = b.fo[sym] + b.count(sym, j)
+	}
+	return string(s)
+}
+
+// MultiBWT is a generalized BWT, a searchable BWT of a collection of
+// strings.
+type MultiBWT struct {
+	bwt    *BWT
+	starts []int // start position of each string in the concatenation
+}
+
+// SeqIndex locates a position within a collection of strings.
+type SeqIndex struct {
+	Seq    int // index of the string in the collection
+	Offset int // position within the string
+}
 
-func (l bwb) Len() int           { return len(l) }
-func (l bwb) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
-func (l bwb) Less(i, j int) bool { return l[i] < l[j] }
+// NewMultiBWT constructs a generalized BWT of a collection of strings.
+//
+// The strings are interpreted byte-wise and must not contain the byte 0.
+// Parameter mod is as for NewBWT.
+//
+// The strings are concatenated, each followed by a 0 byte as a separator.
+// Conceptually each separator is a distinct symbol.  Searches do not match
+// across separators and the partial suffix array holds the start of each
+// string, so that locating a match never maps through a separator.
+func NewMultiBWT(s []string, mod int) *MultiBWT {
+	m := &MultiBWT{starts: make([]int, len(s))}
+	extra := map[int]bool{}
+	n := 0
+	for i, si := range s {
+		m.starts[i] = n
+		extra[n] = true
+		n += len(si) + 1
+	}
+	cat := make([]byte, 0, n)
+	for _, si := range s {
+		cat = append(cat, si...)
+		cat = append(cat, 0)
+	}
+	m.bwt = newBWT(string(cat), mod, extra)
+	return m
+}
+
+// Len returns the number of strings in the collection.
+func (m *MultiBWT) Len() int {
+	return len(m.starts)
+}
+
+// AllIndex returns all indexes of pat in the strings indexed by MultiBWT.
+//
+// Results are ordered by string, then offset.
+func (m *MultiBWT) AllIndex(pat string) []SeqIndex {
+	p := m.bwt.AllIndex(pat)
+	sort.Ints(p)
+	r := make([]SeqIndex, len(p))
+	for i, x := range p {
+		// index of last start <= x
+		seq := sort.SearchInts(m.starts, x+1) - 1
+		r[i] = SeqIndex{seq, x - m.starts[seq]}
+	}
+	return r
+}
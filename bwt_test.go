@@ -2,6 +2,9 @@ package bio_test
 
 import (
 	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
 
 	"github.com/soniakeys/bio"
 )
@@ -14,3 +17,49 @@ func ExampleBWT_AllIndex() {
 	// [11 1]
 	// [15 4]
 }
+
+func ExampleBWT_Inverse() {
+	b := bio.NewBWT("AATCGGGTTCAATCGGGGT", 0, 5)
+	fmt.Println(b.Inverse())
+	// Output:
+	// AATCGGGTTCAATCGGGGT
+}
+
+func ExampleMultiBWT_AllIndex() {
+	m := bio.NewMultiBWT([]string{
+		"AATCGGGTTC",
+		"ATCG",
+		"GGTATCGATCG",
+	}, 3)
+	fmt.Println(m.AllIndex("ATCG"))
+	fmt.Println(m.AllIndex("CA"))
+	// Output:
+	// [{0 1} {1 0} {2 3} {2 7}]
+	// []
+}
+
+func TestMultiBWT(t *testing.T) {
+	s := make([]string, 20)
+	for i := range s {
+		b := make([]byte, rand.Intn(30))
+		for j := range b {
+			b[j] = "AC"[rand.Intn(2)]
+		}
+		s[i] = string(b)
+	}
+	m := bio.NewMultiBWT(s, 4)
+	for _, pat := range []string{"A", "CA", "ACCA", "AAAAA"} {
+		var want []bio.SeqIndex
+		for i, si := range s {
+			for j := 0; j+len(pat) <= len(si); j++ {
+				if si[j:j+len(pat)] == pat {
+					want = append(want, bio.SeqIndex{i, j})
+				}
+			}
+		}
+		if got := m.AllIndex(pat); len(got) != len(want) ||
+			len(got) > 0 && !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: got %v, want %v", pat, got, want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package bio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// bwtio.go
+//
+// Binary serialization of BWT and FMIndex.
+//
+// The format is little-endian:
+//
+//	magic      4 bytes "BWTI"
+//	version    uint32
+//	kind       1 byte, one of the bwtKind constants
+//	mod        uint64
+//	n          uint64, length of the transform
+//	nsym       uint64, number of unique symbols
+//	symbols    nsym bytes, in lexical order, sentinal first
+//	first      nsym uint64s, first occurrence of each symbol
+//	transform  n bytes
+//	npsa       uint64, number of partial suffix array entries
+//	psa        npsa pairs of uint64 row, suffix array value, by row
+//	ncp        uint64, number of checkpoints
+//	cp         ncp*nsym uint64s, counts of each symbol at each checkpoint
+//	text       for FM indexes only, uint64 length followed by the text
+//	checksum   uint32, CRC-32 (IEEE) of all preceding bytes
+
+const (
+	bwtMagic   = "BWTI"
+	bwtVersion = 1
+)
+
+// kinds of serialized index
+const (
+	bwtKindBWT   = 0 // BWT only
+	bwtKindFM    = 1 // FMIndex
+	bwtKindFMDNA = 2 // FMIndex of DNA, searching both strands
+)
+
+// WriteBWT writes a BWT in a versioned and checksummed binary format.
+//
+// The BWT can be recovered with ReadBWT or LoadBWT.
+func WriteBWT(w io.Writer, b *BWT) error {
+	return writeBWT(w, b, bwtKindBWT, nil)
+}
+
+// WriteFMIndex writes an FMIndex in a versioned and checksummed binary
+// format.
+//
+// The index can be recovered with ReadFMIndex or LoadFMIndex.
+func WriteFMIndex(w io.Writer, x *FMIndex) error {
+	kind := byte(bwtKindFM)
+	if x.dna {
+		kind = bwtKindFMDNA
+	}
+	return writeBWT(w, x.bwt, kind, x.text)
+}
+
+func writeBWT(w io.Writer, b *BWT, kind byte, text []byte) error {
+	crc := crc32.NewIEEE()
+	bw := bufio.NewWriter(io.MultiWriter(w, crc))
+	var buf [8]byte
+	u64 := func(v int) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		bw.Write(buf[:])
+	}
+	bw.WriteString(bwtMagic)
+	binary.LittleEndian.PutUint32(buf[:], bwtVersion)
+	bw.Write(buf[:4])
+	bw.WriteByte(kind)
+	u64(b.mod)
+	u64(len(b.bwt))
+	u64(len(b.sx))
+	bw.Write(b.sx)
+	for _, s := range b.sx {
+		u64(b.fo[s])
+	}
+	bw.Write(b.bwt)
+	u64(b.psa.len() / 2)
+	bw.Write(b.psa)
+	u64(b.cp.len() / len(b.sx))
+	bw.Write(b.cp)
+	if kind != bwtKindBWT {
+		u64(len(text))
+		bw.Write(text)
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(buf[:], crc.Sum32())
+	_, err := w.Write(buf[:4])
+	return err
+}
+
+// ReadBWT reads a BWT written by WriteBWT.
+//
+// If alphabet is not empty, all symbols of the BWT other than the sentinal
+// must be in alphabet.  An error is returned for a version, checksum, or
+// alphabet mismatch.  The BWT of an FMIndex may be read as well.
+func ReadBWT(r io.Reader, alphabet string) (*BWT, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return LoadBWT(data, alphabet)
+}
+
+// LoadBWT loads a BWT from data written by WriteBWT.
+//
+// Data may be the contents of a memory mapped file.  The returned BWT
+// shares memory with data, which must not be modified or unmapped while
+// the BWT is in use.  The transform, partial suffix array, and checkpoints
+// are all used in place.  Loading takes a pass over data to verify the
+// checksum and a pass over the transform to verify the checkpoints, but
+// allocates only a little memory.  LoadBWT is otherwise the same as
+// ReadBWT.
+func LoadBWT(data []byte, alphabet string) (*BWT, error) {
+	b, _, _, err := loadBWT(data, alphabet)
+	return b, err
+}
+
+// ReadFMIndex reads an FMIndex written by WriteFMIndex.
+//
+// Alphabet and errors are as for ReadBWT.
+func ReadFMIndex(r io.Reader, alphabet string) (*FMIndex, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFMIndex(data, alphabet)
+}
+
+// LoadFMIndex loads an FMIndex from data written by WriteFMIndex.
+//
+// As with LoadBWT, the returned index shares memory with data.
+func LoadFMIndex(data []byte, alphabet string) (*FMIndex, error) {
+	b, kind, text, err := loadBWT(data, alphabet)
+	if err != nil {
+		return nil, err
+	}
+	if kind == bwtKindBWT {
+		return nil, errors.New("BWT index: not an FM index")
+	}
+	return &FMIndex{bwt: b, text: text, dna: kind == bwtKindFMDNA}, nil
+}
+
+// bwtDecoder decodes serialized BWT data, recording the first error.
+type bwtDecoder struct {
+	data []byte
+	p    int
+	err  error
+}
+
+var errBWTData = errors.New("BWT index: invalid data")
+
+func (d *bwtDecoder) bytes(n int) []byte {
+	if d.err != nil || n < 0 || n > len(d.data)-d.p {
+		d.err = errBWTData
+		return nil
+	}
+	b := d.data[d.p : d.p+n : d.p+n]
+	d.p += n
+	return b
+}
+
+// count decodes a number of items of size sz, checking that the data
+// remaining could hold them.
+func (d *bwtDecoder) count(sz int) int {
+	n := d.int()
+	if d.err != nil {
+		return 0
+	}
+	if n < 0 || n > (len(d.data)-d.p)/sz {
+		d.err = errBWTData
+		return 0
+	}
+	return n
+}
+
+func (d *bwtDecoder) int() int {
+	b := d.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint64(b))
+}
+
+func loadBWT(data []byte, alphabet string) (b *BWT, kind byte, text []byte, err error) {
+	if len(data) < 13 || string(data[:4]) != bwtMagic {
+		return nil, 0, nil, errBWTData
+	}
+	if v := binary.LittleEndian.Uint32(data[4:]); v != bwtVersion {
+		return nil, 0, nil, fmt.Errorf("BWT index: unsupported version %d", v)
+	}
+	n := len(data) - 4
+	if crc32.ChecksumIEEE(data[:n]) != binary.LittleEndian.Uint32(data[n:]) {
+		return nil, 0, nil, errors.New("BWT index: checksum mismatch")
+	}
+	d := &bwtDecoder{data: data[:n], p: 8}
+	kind = d.bytes(1)[0]
+	if kind > bwtKindFMDNA {
+		return nil, 0, nil, errBWTData
+	}
+	b = &BWT{mod: d.int()}
+	nb := d.count(1)
+	b.sx = d.bytes(d.count(1))
+	if d.err == nil && (len(b.sx) == 0 || b.mod <= 0) {
+		d.err = errBWTData
+	}
+	for i, s := range b.sx {
+		if i > 0 && s <= b.sx[i-1] {
+			d.err = errBWTData
+			break
+		}
+		if i > 0 && alphabet != "" && strings.IndexByte(alphabet, s) < 0 {
+			return nil, 0, nil, fmt.Errorf("BWT index: symbol %q not in alphabet", s)
+		}
+		b.fo[s] = d.int()
+		b.cx[s] = i
+	}
+	b.bwt = d.bytes(nb)
+	b.psa = d.bytes(16 * d.count(16))
+	ncp := d.count(8 * len(b.sx))
+	if d.err == nil && ncp != nb/b.mod+1 {
+		d.err = errBWTData
+	}
+	b.cp = d.bytes(8 * ncp * len(b.sx))
+	if kind != bwtKindBWT {
+		text = d.bytes(d.count(1))
+		if d.err == nil && len(text) != nb-1 {
+			d.err = errBWTData
+		}
+	}
+	if d.err == nil && (d.p != len(d.data) || !b.valid()) {
+		d.err = errBWTData
+	}
+	if d.err != nil {
+		return nil, 0, nil, d.err
+	}
+	return b, kind, text, nil
+}
+
+// valid checks values of a loaded BWT that could otherwise cause a panic
+// in searches.  First occurrences and checkpoints must agree with the
+// symbols of the transform.  Partial suffix array rows must be in
+// increasing order and rows and values must be in range.
+func (b *BWT) valid() bool {
+	n := len(b.bwt)
+	np := b.psa.len() / 2
+	if np == 0 {
+		return false
+	}
+	for x := 0; x < np; x++ {
+		j, v := b.psa.at(2*x), b.psa.at(2*x+1)
+		if j < 0 || j >= n || v < 0 || v >= n ||
+			x > 0 && j <= b.psa.at(2*x-2) {
+			return false
+		}
+	}
+	ns := len(b.sx)
+	counts := make([]int, ns)
+	checkpoint := func(m int) bool {
+		for k, c := range counts {
+			if b.cp.at(m*ns+k) != c {
+				return false
+			}
+		}
+		return true
+	}
+	for i, c := range b.bwt {
+		if i%b.mod == 0 && !checkpoint(i/b.mod) || !b.has(c) {
+			return false
+		}
+		counts[b.cx[c]]++
+	}
+	if n%b.mod == 0 && !checkpoint(n/b.mod) {
+		return false
+	}
+	f := 0
+	for k, s := range b.sx {
+		if b.fo[s] != f {
+			return false
+		}
+		f += counts[k]
+	}
+	return true
+}
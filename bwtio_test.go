@@ -0,0 +1,83 @@
+package bio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleWriteBWT() {
+	var buf bytes.Buffer
+	bio.WriteBWT(&buf, bio.NewBWT("AATCGGGTTCAATCGGGGT", 0, 5))
+	b, err := bio.LoadBWT(buf.Bytes(), "ACGT")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(b.AllIndex("ATCG"))
+	// Output:
+	// [11 1]
+}
+
+func ExampleReadFMIndex() {
+	var buf bytes.Buffer
+	x := bio.NewFMIndexDNA8(bio.DNA8("ACGTTGCAGGACTTACGAAT"), 4)
+	bio.WriteFMIndex(&buf, x)
+	data := buf.Bytes()
+
+	y, err := bio.ReadFMIndex(bytes.NewReader(data), "ACGT")
+	fmt.Println(err)
+	fmt.Println(len(y.Search([]byte("TTGC"), 1)))
+
+	_, err = bio.ReadFMIndex(bytes.NewReader(data), "ACG")
+	fmt.Println(err)
+
+	data[30] ^= 1
+	_, err = bio.ReadFMIndex(bytes.NewReader(data), "")
+	fmt.Println(err)
+
+	data[4] = 9
+	_, err = bio.ReadFMIndex(bytes.NewReader(data), "")
+	fmt.Println(err)
+	// Output:
+	// <nil>
+	// 3
+	// BWT index: symbol 'T' not in alphabet
+	// BWT index: checksum mismatch
+	// BWT index: unsupported version 9
+}
+
+// TestLoadFMIndexCorrupt changes each byte of a serialized index, with
+// a valid checksum.  Loading must fail or give an index that can be
+// searched without panicking.
+func TestLoadFMIndexCorrupt(t *testing.T) {
+	var buf bytes.Buffer
+	bio.WriteFMIndex(&buf, bio.NewFMIndex([]byte("ACGTTGCAGGACTTACGAAT"), 3))
+	good := buf.Bytes()
+	n := len(good) - 4
+	for p := 13; p < n; p++ {
+		for _, x := range []byte{1, 0x80} {
+			data := append([]byte{}, good...)
+			data[p] ^= x
+			binary.LittleEndian.PutUint32(data[n:],
+				crc32.ChecksumIEEE(data[:n]))
+			ix, err := bio.LoadFMIndex(data, "")
+			if err != nil {
+				continue
+			}
+			func() {
+				defer func() {
+					if e := recover(); e != nil {
+						t.Fatalf("byte %d ^ %#x: %v", p, x, e)
+					}
+				}()
+				ix.Search([]byte("GCA"), 1)
+				ix.SeedSearch([]byte("ACGA"), 1)
+			}()
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package bio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CodonUsage holds codon counts for a genetic code.
+//
+// Counts are indexed by CodonIndex.  They are float64 so that tables of
+// frequencies rather than counts can be used as well.
+type CodonUsage struct {
+	Code   *GeneticCode
+	Counts [64]float64
+}
+
+// NewCodonUsage constructs an empty CodonUsage for genetic code c.
+//
+// If c is nil, the standard code is used.
+func NewCodonUsage(c *GeneticCode) *CodonUsage {
+	if c == nil {
+		c = StandardCode
+	}
+	return &CodonUsage{Code: c}
+}
+
+// CountCodons constructs a CodonUsage from coding sequences using the
+// standard code.
+func CountCodons(cds []DNA8) *CodonUsage {
+	u := NewCodonUsage(nil)
+	for _, s := range cds {
+		u.Add(s)
+	}
+	return u
+}
+
+// Add counts the codons of coding sequence s.
+//
+// Codons are counted in frame from the start of s.  An incomplete codon
+// at the end is ignored.
+func (u *CodonUsage) Add(s DNA8) {
+	for p := 0; p+3 <= len(s); p += 3 {
+		u.Counts[CodonIndex(s[p], s[p+1], s[p+2])]++
+	}
+}
+
+// codonString returns the codon for a CodonIndex as upper case DNA.
+func codonString(x int) DNA8 {
+	return DNA8{"ACTG"[x>>4], "ACTG"[x>>2&3], "ACTG"[x&3]}
+}
+
+// synonyms returns, for each amino acid or AAStop, the CodonIndexes that
+// code for it.
+func (c *GeneticCode) synonyms() map[byte][]int {
+	m := map[byte][]int{}
+	for x, aa := range c.table {
+		m[aa] = append(m[aa], x)
+	}
+	return m
+}
+
+// Fraction returns the count of each codon as a fraction of the total
+// count of its synonymous codons.
+//
+// Fractions are 0 for amino acids with no counts.
+func (u *CodonUsage) Fraction() (f [64]float64) {
+	for _, syn := range u.Code.synonyms() {
+		t := 0.
+		for _, x := range syn {
+			t += u.Counts[x]
+		}
+		if t > 0 {
+			for _, x := range syn {
+				f[x] = u.Counts[x] / t
+			}
+		}
+	}
+	return
+}
+
+// RSCU returns the relative synonymous codon usage of each codon.
+//
+// RSCU is the count of a codon divided by the mean count of its synonymous
+// codons, so that codons used without bias have RSCU 1.
+// RSCU is 0 for amino acids with no counts.
+func (u *CodonUsage) RSCU() (r [64]float64) {
+	f := u.Fraction()
+	for _, syn := range u.Code.synonyms() {
+		for _, x := range syn {
+			r[x] = f[x] * float64(len(syn))
+		}
+	}
+	return
+}
+
+// weights returns the relative adaptiveness of each codon, the count of
+// the codon divided by the count of the most used synonymous codon.
+// Zero counts are taken as 0.5, following Sharp and Li.
+func (u *CodonUsage) weights() (w [64]float64) {
+	for _, syn := range u.Code.synonyms() {
+		max := 0.
+		for _, x := range syn {
+			max = math.Max(max, u.Counts[x])
+		}
+		for _, x := range syn {
+			if max > 0 {
+				w[x] = math.Max(u.Counts[x], .5) / max
+			}
+		}
+	}
+	return
+}
+
+// CAI computes the codon adaptation index of coding sequence s with the
+// receiver as the reference codon usage.
+//
+// CAI is the geometric mean of the relative adaptiveness of the codons of s.
+// Stop codons and codons of amino acids with a single codon are excluded.
+// Reference: Sharp and Li 1987, "The codon adaptation index", Nucleic
+// Acids Research 15:1281.
+func (u *CodonUsage) CAI(s DNA8) float64 {
+	w := u.weights()
+	syn := u.Code.synonyms()
+	sum := 0.
+	n := 0
+	for p := 0; p+3 <= len(s); p += 3 {
+		x := CodonIndex(s[p], s[p+1], s[p+2])
+		aa := u.Code.table[x]
+		if aa == AAStop || len(syn[aa]) == 1 || w[x] == 0 {
+			continue
+		}
+		sum += math.Log(w[x])
+		n++
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return math.Exp(sum / float64(n))
+}
+
+// ENC computes the effective number of codons of the receiver usage.
+//
+// Amino acids are grouped by their number of synonymous codons.  For each
+// amino acid with a count of at least two, the homozygosity
+// F = (n Σp² - 1) / (n - 1) is computed and F is averaged within each
+// group.  ENC is the sum over groups of the number of amino acids in the
+// group divided by the average F.  A missing average for the group of
+// three codons, that is of isoleucine in the standard code, is taken as
+// the mean of the groups of two and four.  If any other group has no
+// data, the result is NaN.  The result is at most the number of sense
+// codons.
+//
+// Reference: Wright 1990, "The 'effective number of codons' used in
+// a gene", Gene 87:23.
+func (u *CodonUsage) ENC() float64 {
+	type group struct {
+		naa  int     // number of amino acids in group
+		sumF float64 // sum of F
+		nF   int     // number of amino acids contributing to sumF
+	}
+	g := map[int]*group{}
+	sense := 0
+	for aa, syn := range u.Code.synonyms() {
+		if aa == AAStop {
+			continue
+		}
+		sense += len(syn)
+		k := len(syn)
+		if g[k] == nil {
+			g[k] = &group{}
+		}
+		gr := g[k]
+		gr.naa++
+		if k == 1 {
+			gr.sumF++
+			gr.nF++
+			continue
+		}
+		n := 0.
+		for _, x := range syn {
+			n += u.Counts[x]
+		}
+		if n < 2 {
+			continue
+		}
+		s2 := 0.
+		for _, x := range syn {
+			p := u.Counts[x] / n
+			s2 += p * p
+		}
+		gr.sumF += (n*s2 - 1) / (n - 1)
+		gr.nF++
+	}
+	avg := func(k int) float64 {
+		if gr := g[k]; gr != nil && gr.nF > 0 {
+			return gr.sumF / float64(gr.nF)
+		}
+		return math.NaN()
+	}
+	enc := 0.
+	for k, gr := range g {
+		f := avg(k)
+		if math.IsNaN(f) && k == 3 {
+			f = (avg(2) + avg(4)) / 2
+		}
+		enc += float64(gr.naa) / f
+	}
+	if enc > float64(sense) {
+		enc = float64(sense)
+	}
+	return enc
+}
+
+// BackTranslate returns a coding sequence for peptide p using the most
+// frequent codon for each amino acid.
+//
+// For amino acids with no counts, the first codon in ACTG order is used.
+// An error is returned for symbols with no codon in the genetic code.
+func (u *CodonUsage) BackTranslate(p AA20) (DNA8, error) {
+	syn := u.Code.synonyms()
+	s := make(DNA8, 0, 3*len(p))
+	for _, aa := range p {
+		xs := syn[aa]
+		if len(xs) == 0 {
+			return nil, fmt.Errorf("no codon for %q", aa)
+		}
+		best := xs[0]
+		for _, x := range xs[1:] {
+			if u.Counts[x] > u.Counts[best] {
+				best = x
+			}
+		}
+		s = append(s, codonString(best)...)
+	}
+	return s, nil
+}
+
+// BackTranslateRandom returns a coding sequence for peptide p with codons
+// chosen at random, weighted by codon counts.
+//
+// For amino acids with no counts, codons are chosen uniformly.  An error
+// is returned for symbols with no codon in the genetic code.
+func (u *CodonUsage) BackTranslateRandom(p AA20) (DNA8, error) {
+	syn := u.Code.synonyms()
+	s := make(DNA8, 0, 3*len(p))
+	for _, aa := range p {
+		xs := syn[aa]
+		if len(xs) == 0 {
+			return nil, fmt.Errorf("no codon for %q", aa)
+		}
+		t := 0.
+		for _, x := range xs {
+			t += u.Counts[x]
+		}
+		c := xs[rand.Intn(len(xs))]
+		if t > 0 {
+			f := rand.Float64() * t
+			for _, x := range xs {
+				if u.Counts[x] > 0 {
+					c = x
+					if f -= u.Counts[x]; f < 0 {
+						break
+					}
+				}
+			}
+		}
+		s = append(s, codonString(c)...)
+	}
+	return s, nil
+}
+
+// codonUsageHeader is the column header line of the codon usage table
+// format, that of EMBOSS cusp.
+const codonUsageHeader = "#Codon AA Fraction Frequency Number"
+
+// WriteCodonUsage writes a codon usage table in the format of EMBOSS cusp.
+//
+// Columns are the codon, the amino acid, the fraction among synonymous
+// codons, the frequency per thousand codons, and the count.  Rows are
+// sorted by amino acid, then codon.
+func WriteCodonUsage(w io.Writer, u *CodonUsage) error {
+	// sort keys of amino acid followed by codon
+	keys := make([]string, 64)
+	for i := range keys {
+		keys[i] = string(u.Code.table[i]) + string(codonString(i))
+	}
+	sort.Strings(keys)
+	total := 0.
+	for _, c := range u.Counts {
+		total += c
+	}
+	f := u.Fraction()
+	bw := bufio.NewWriter(w)
+	bw.WriteString(codonUsageHeader + "\n")
+	for _, k := range keys {
+		i := CodonIndex(k[1], k[2], k[3])
+		per1000 := 0.
+		if total > 0 {
+			per1000 = u.Counts[i] / total * 1000
+		}
+		fmt.Fprintf(bw, "%s    %c %9.3f %9.3f %6s\n", codonString(i),
+			u.Code.table[i], f[i], per1000,
+			strconv.FormatFloat(u.Counts[i], 'f', -1, 64))
+	}
+	return bw.Flush()
+}
+
+// ReadCodonUsage reads a codon usage table in the format of EMBOSS cusp,
+// as written by WriteCodonUsage.
+//
+// Codons may be DNA or RNA.  Lines starting with # are ignored.  Counts are
+// taken from the Number column, unless all counts are zero, in which case
+// they are taken from the Frequency column.  The amino acid column is not
+// checked against genetic code c.  If c is nil, the standard code is used.
+func ReadCodonUsage(r io.Reader, c *GeneticCode) (*CodonUsage, error) {
+	u := NewCodonUsage(c)
+	var freq [64]float64
+	br := bufio.NewReader(r)
+	sum := 0.
+	for ln := 1; ; ln++ {
+		b, err := readLine(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line := strings.TrimSpace(string(b))
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) < 5 || len(f[0]) != 3 {
+			return nil, fmt.Errorf("codon usage line %d: invalid line", ln)
+		}
+		for _, b := range []byte(strings.ToUpper(f[0])) {
+			if strings.IndexByte("ACGTU", b) < 0 {
+				return nil, fmt.Errorf("codon usage line %d: invalid codon %s",
+					ln, f[0])
+			}
+		}
+		x := CodonIndex(f[0][0], f[0][1], f[0][2])
+		fr, err1 := strconv.ParseFloat(f[3], 64)
+		n, err2 := strconv.ParseFloat(f[4], 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("codon usage line %d: invalid number", ln)
+		}
+		freq[x] = fr
+		u.Counts[x] = n
+		sum += n
+	}
+	if sum == 0 {
+		u.Counts = freq
+	}
+	return u, nil
+}
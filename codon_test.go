@@ -0,0 +1,114 @@
+package bio_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+var codonTestCDS = []bio.DNA8{
+	bio.DNA8("ATGGCTGCTGCAAAAAAGCTGCTGTTATAA"),
+	bio.DNA8("ATGGCCGCTAAACTGCTGCTGTGA"),
+}
+
+func ExampleCodonUsage_RSCU() {
+	u := bio.CountCodons(codonTestCDS)
+	r := u.RSCU()
+	for _, c := range []string{"GCT", "GCC", "GCA", "GCG", "CTG", "TTA"} {
+		fmt.Printf("%s %.2f\n", c, r[bio.CodonIndex(c[0], c[1], c[2])])
+	}
+	// Output:
+	// GCT 2.40
+	// GCC 0.80
+	// GCA 0.80
+	// GCG 0.00
+	// CTG 5.00
+	// TTA 1.00
+}
+
+func ExampleCodonUsage_CAI() {
+	u := bio.CountCodons(codonTestCDS)
+	fmt.Printf("%.3f\n", u.CAI(bio.DNA8("ATGGCTAAACTGTAA")))
+	fmt.Printf("%.3f\n", u.CAI(bio.DNA8("ATGGCGAAGCTGTAA")))
+	// Output:
+	// 1.000
+	// 0.437
+}
+
+func ExampleCodonUsage_ENC() {
+	// one codon per amino acid gives the minimum, 20
+	u := bio.NewCodonUsage(nil)
+	u.Add(bio.DNA8("GCTCGTAATGATTGTCAAGAAGGTCATATTCTTAAAATGTTTCCTTCTACTTGGTATGTT"))
+	u.Add(bio.DNA8("GCTCGTAATGATTGTCAAGAAGGTCATATTCTTAAAATGTTTCCTTCTACTTGGTATGTT"))
+	fmt.Printf("%.1f\n", u.ENC())
+	// uniform usage gives the maximum, 61
+	for i := range u.Counts {
+		u.Counts[i] = 10
+	}
+	fmt.Printf("%.1f\n", u.ENC())
+	// Output:
+	// 20.0
+	// 61.0
+}
+
+func ExampleCodonUsage_BackTranslate() {
+	u := bio.CountCodons(codonTestCDS)
+	fmt.Println(u.BackTranslate(bio.AA20("MAKLW")))
+	// Output:
+	// ATGGCTAAACTGTGG <nil>
+}
+
+func TestBackTranslateRandom(t *testing.T) {
+	u := bio.CountCodons(codonTestCDS)
+	p := bio.AA20("MAKLLAAK")
+	for i := 0; i < 20; i++ {
+		s, err := u.BackTranslateRandom(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tr, _ := s.Translate(); string(tr) != string(p) {
+			t.Fatalf("%s translates to %s", s, tr)
+		}
+		// GCG and TTG have zero counts and should never be chosen
+		if bytes.Contains(s, []byte("GCG")) || bytes.Contains(s, []byte("TTG")) {
+			t.Fatalf("%s uses a codon with zero count", s)
+		}
+	}
+}
+
+func TestCodonUsageTable(t *testing.T) {
+	u := bio.CountCodons(codonTestCDS)
+	var b bytes.Buffer
+	if err := bio.WriteCodonUsage(&b, u); err != nil {
+		t.Fatal(err)
+	}
+	v, err := bio.ReadCodonUsage(&b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Counts != u.Counts {
+		t.Fatal("counts differ after round trip")
+	}
+	if math.Abs(v.CAI(codonTestCDS[0])-u.CAI(codonTestCDS[0])) > 1e-12 {
+		t.Fatal("CAI differs")
+	}
+}
+
+func ExampleWriteCodonUsage() {
+	u := bio.CountCodons(codonTestCDS)
+	var b bytes.Buffer
+	bio.WriteCodonUsage(&b, u)
+	for _, line := range strings.Split(b.String(), "\n")[:5] {
+		fmt.Println(line)
+	}
+	// Output:
+	// #Codon AA Fraction Frequency Number
+	// TAA    *     0.500    55.556      1
+	// TAG    *     0.000     0.000      0
+	// TGA    *     0.500    55.556      1
+	// GCA    A     0.200    55.556      1
+}
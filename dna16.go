@@ -0,0 +1,199 @@
+package bio
+
+import "fmt"
+
+// DNA16 type represents a DNA sequence that may contain IUPAC ambiguity
+// symbols.
+//
+// Each element is a set of bases encoded in the low 4 bits: A = 1, C = 2,
+// G = 4, T = 8.  Ambiguity symbols are unions of these, so for example
+// R (A or G) is 5 and N is 15.  The value 0, the empty set, represents a gap.
+// The encoding is that of the 4-bit bases of the BAM format.
+//
+// Case is not represented.  The String method returns upper case symbols.
+type DNA16 []byte
+
+// DNA16Symbols holds the IUPAC symbol for each DNA16 base set.
+const DNA16Symbols = "-ACMGRSVTWYHKDBN"
+
+// Base sets of DNA16.
+const (
+	DNA16A   = 1
+	DNA16C   = 2
+	DNA16G   = 4
+	DNA16T   = 8
+	DNA16N   = 15
+	DNA16Gap = 0
+)
+
+// dna16Set maps symbols to base sets, with 0xff for invalid symbols.
+var dna16Set [256]byte
+
+// dna16Count holds the number of bases in each set.
+var dna16Count [16]int
+
+func init() {
+	for i := range dna16Set {
+		dna16Set[i] = 0xff
+	}
+	for i := 0; i < 16; i++ {
+		b := DNA16Symbols[i]
+		dna16Set[b] = byte(i)
+		dna16Set[b|LCBit] = byte(i)
+		dna16Count[i] = i&1 + i>>1&1 + i>>2&1 + i>>3
+	}
+	dna16Set['U'] = DNA16T
+	dna16Set['u'] = DNA16T
+}
+
+// ParseDNA16 converts a string of IUPAC symbols to DNA16.
+//
+// Upper and lower case symbols are allowed.  U is accepted as T and
+// '-' as a gap.  Other symbols give an error.
+func ParseDNA16(s string) (DNA16, error) {
+	d := make(DNA16, len(s))
+	for i := 0; i < len(s); i++ {
+		b := dna16Set[s[i]]
+		if b == 0xff {
+			return nil, fmt.Errorf("invalid IUPAC DNA symbol %q at position %d",
+				s[i], i)
+		}
+		d[i] = b
+	}
+	return d, nil
+}
+
+// DNA16 converts a DNA8 sequence to DNA16.
+func (s DNA8) DNA16() DNA16 {
+	d := make(DNA16, len(s))
+	for i, b := range s {
+		d[i] = dna16Set[b]
+	}
+	return d
+}
+
+// String returns the IUPAC symbols of the sequence, satisfying fmt.Stringer.
+func (s DNA16) String() string {
+	b := make([]byte, len(s))
+	for i, x := range s {
+		b[i] = DNA16Symbols[x&15]
+	}
+	return string(b)
+}
+
+// DNA16Complement returns the complement of a DNA16 base set.
+//
+// The result agrees with DNAComplement on the corresponding symbols.
+func DNA16Complement(b byte) byte {
+	// A<->T, C<->G is reversal of the four bits.
+	return b&1<<3 | b&2<<1 | b&4>>1 | b&8>>3
+}
+
+// ReverseComplement returns the reverse complement of the receiver.
+//
+// A new sequence is returned.  The receiver is unmodified.
+func (s DNA16) ReverseComplement() DNA16 {
+	rc := make(DNA16, len(s))
+	for i, b := range s {
+		rc[len(s)-1-i] = DNA16Complement(b)
+	}
+	return rc
+}
+
+// Match returns true if sequences s and t are of equal length and at each
+// position the base sets share at least one base.
+//
+// Thus N matches any base, R matches A, G, R, and N, and so on.  A gap
+// matches nothing, not even another gap.
+func (s DNA16) Match(t DNA16) bool {
+	if len(s) != len(t) {
+		return false
+	}
+	for i, b := range s {
+		if b&t[i] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchDNA8 returns true if DNA8 sequence t is one of the sequences
+// represented by receiver s.
+func (s DNA16) MatchDNA8(t DNA8) bool {
+	if len(s) != len(t) {
+		return false
+	}
+	for i, b := range s {
+		if b&dna16Set[t[i]] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// DegenerateAllIndex returns indexes of all occurrences of degenerate
+// motif m in s, where the symbols of s match those of m as by MatchDNA8.
+//
+// Overlapping occurrences are found.
+func (s DNA8) DegenerateAllIndex(m DNA16) (x []int) {
+	for i := 0; i+len(m) <= len(s); i++ {
+		if m.MatchDNA8(s[i : i+len(m)]) {
+			x = append(x, i)
+		}
+	}
+	return
+}
+
+// Degeneracy returns the number of concrete DNA sequences represented by s,
+// the product of the number of bases in each set.
+//
+// The result is 0 if s contains a gap.  The result may overflow for long
+// sequences with many ambiguous positions.
+func (s DNA16) Degeneracy() int {
+	n := 1
+	for _, b := range s {
+		n *= dna16Count[b&15]
+	}
+	return n
+}
+
+// Expand returns all concrete DNA8 sequences represented by s.
+//
+// Results are upper case and in order of the base values, ACGT.
+// The number of results is s.Degeneracy().
+func (s DNA16) Expand() Kmers {
+	x := Kmers{make(DNA8, 0, len(s))}
+	for _, b := range s {
+		var next Kmers
+		for _, k := range x {
+			for _, base := range []byte{DNA16A, DNA16C, DNA16G, DNA16T} {
+				if b&base != 0 {
+					next = append(next, append(k[:len(k):len(k)],
+						DNA16Symbols[base]))
+				}
+			}
+		}
+		x = next
+	}
+	return x
+}
+
+// DNA16Consensus returns a degenerate consensus of receiver Kmers.
+//
+// The base set at each position is the union of the bases of the kmers
+// at that position.  The result length is that of the first kmer.
+// Kmers of other lengths are allowed, with shortages and excesses ignored.
+func (x Kmers) DNA16Consensus() DNA16 {
+	if len(x) == 0 {
+		return nil
+	}
+	c := make(DNA16, len(x[0]))
+	for _, k := range x {
+		for i, b := range k {
+			if i < len(c) {
+				c[i] |= dna16Set[b] & 15
+			}
+		}
+	}
+	return c
+}
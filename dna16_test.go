@@ -0,0 +1,76 @@
+package bio_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleParseDNA16() {
+	s, err := bio.ParseDNA16("ACGTrykmswbdhvn-")
+	fmt.Println(s, err)
+	fmt.Println([]byte(s))
+	_, err = bio.ParseDNA16("ACX")
+	fmt.Println(err)
+	// Output:
+	// ACGTRYKMSWBDHVN- <nil>
+	// [1 2 4 8 5 10 12 3 6 9 14 13 11 7 15 0]
+	// invalid IUPAC DNA symbol 'X' at position 2
+}
+
+func ExampleDNA16_ReverseComplement() {
+	s, _ := bio.ParseDNA16("AACRYN")
+	fmt.Println(s.ReverseComplement())
+	// Output:
+	// NRYGTT
+}
+
+func TestDNA16Complement(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		b := bio.DNA16Symbols[i]
+		c := bio.DNA16Symbols[bio.DNA16Complement(byte(i))]
+		if b != '-' && c != bio.DNAComplement(b) {
+			t.Fatalf("complement of %c = %c, DNAComplement = %c",
+				b, c, bio.DNAComplement(b))
+		}
+	}
+}
+
+func ExampleDNA16_Match() {
+	p, _ := bio.ParseDNA16("ACNT")
+	q, _ := bio.ParseDNA16("AYGW")
+	fmt.Println(p.Match(q))
+	fmt.Println(p.MatchDNA8(bio.DNA8("acgt")), p.MatchDNA8(bio.DNA8("acga")))
+	// Output:
+	// true
+	// true false
+}
+
+func ExampleDNA8_DegenerateAllIndex() {
+	// EcoRII site
+	m, _ := bio.ParseDNA16("CCWGG")
+	fmt.Println(bio.DNA8("CCAGGTTCCTGGACCCGG").DegenerateAllIndex(m))
+	// Output:
+	// [0 7]
+}
+
+func ExampleDNA16_Expand() {
+	s, _ := bio.ParseDNA16("ARYN")
+	fmt.Println(s.Degeneracy())
+	fmt.Println(s.Expand()[:6])
+	// Output:
+	// 16
+	// [AACA AACC AACG AACT AATA AATC]
+}
+
+func ExampleKmers_DNA16Consensus() {
+	x := bio.Kmers{
+		bio.DNA8("ACGTA"),
+		bio.DNA8("ACGTG"),
+		bio.DNA8("ATGAC"),
+	}
+	fmt.Println(x.DNA16Consensus())
+	// Output:
+	// AYGWV
+}
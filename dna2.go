@@ -0,0 +1,441 @@
+package bio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// DNA2 represents a DNA sequence packed four bases to a byte.
+//
+// Bases are coded in two bits in the order ACTG, that is A = 0, C = 1,
+// T = 2, G = 3, the same as bits 1 and 2 of the ASCII symbols.  The first
+// base of each byte is in the most significant bits.
+//
+// Positions that are not ACTG, typically N, are recorded in a side table
+// of N blocks and are stored with code 0.  Lower case, as used for soft
+// masking of repeats, is recorded in a second side table.
+//
+// Slicing with the Slice method does not copy the packed data.
+type DNA2 struct {
+	b     []byte     // packed bases
+	off   int        // offset in bases of the first base in b
+	n     int        // length in bases
+	nb    []Interval // N blocks, in base coordinates of b, sorted
+	lower []Interval // lower case blocks, in base coordinates of b, sorted
+}
+
+// NewDNA2 packs a sequence of DNA symbols.
+//
+// Symbols ACTGactg are packed.  Any other symbol is recorded as N.
+// Lower case symbols are recorded in the lower case mask.
+func NewDNA2(s []byte) DNA2 {
+	d := DNA2{b: make([]byte, (len(s)+3)/4), n: len(s)}
+	for i, c := range s {
+		switch c | LCBit {
+		case 'a', 'c', 't', 'g':
+			d.b[i>>2] |= c >> 1 & 3 << (6 - 2*uint(i&3))
+		default:
+			d.nb = addBlock(d.nb, i)
+		}
+		if c >= 'a' && c <= 'z' {
+			d.lower = addBlock(d.lower, i)
+		}
+	}
+	return d
+}
+
+// addBlock adds position p to a list of blocks, extending the last block
+// if p is adjacent to it.
+func addBlock(b []Interval, p int) []Interval {
+	if len(b) > 0 && b[len(b)-1].End == p {
+		b[len(b)-1].End++
+		return b
+	}
+	return append(b, Interval{p, p + 1})
+}
+
+// DNA2 packs a DNA8 sequence.
+func (s DNA8) DNA2() DNA2 { return NewDNA2(s) }
+
+// Len returns the number of bases in the sequence.
+func (s DNA2) Len() int { return s.n }
+
+// Code returns the two bit code of the base at position i.
+//
+// The code of an N is 0.
+func (s DNA2) Code(i int) byte {
+	i += s.off
+	return s.b[i>>2] >> (6 - 2*uint(i&3)) & 3
+}
+
+// Slice returns the subsequence of positions i:j.
+//
+// The packed data is shared, not copied.
+func (s DNA2) Slice(i, j int) DNA2 {
+	if i < 0 || j > s.n || i > j {
+		panic(fmt.Sprintf("DNA2 slice [%d:%d] out of range for length %d",
+			i, j, s.n))
+	}
+	s.off += i
+	s.n = j - i
+	return s
+}
+
+// clip returns blocks b restricted to the sequence, relative to the start
+// of the sequence.
+func (s DNA2) clip(b []Interval) (r []Interval) {
+	i := sort.Search(len(b), func(i int) bool { return b[i].End > s.off })
+	for ; i < len(b) && b[i].Start < s.off+s.n; i++ {
+		iv := Interval{b[i].Start - s.off, b[i].End - s.off}
+		if iv.Start < 0 {
+			iv.Start = 0
+		}
+		if iv.End > s.n {
+			iv.End = s.n
+		}
+		r = append(r, iv)
+	}
+	return
+}
+
+// NBlocks returns the blocks of N positions in the sequence.
+func (s DNA2) NBlocks() []Interval { return s.clip(s.nb) }
+
+// LowerBlocks returns the blocks of lower case positions in the sequence.
+func (s DNA2) LowerBlocks() []Interval { return s.clip(s.lower) }
+
+// DNA8 unpacks the sequence.
+//
+// N positions are unpacked as N, or n if also lower case.  Note that N is
+// not a DNA8 symbol.  The result will be valid DNA8 only if the sequence
+// has no N blocks.
+func (s DNA2) DNA8() DNA8 {
+	d := make(DNA8, s.n)
+	for i := range d {
+		d[i] = "ACTG"[s.Code(i)]
+	}
+	for _, iv := range s.NBlocks() {
+		for i := iv.Start; i < iv.End; i++ {
+			d[i] = 'N'
+		}
+	}
+	for _, iv := range s.LowerBlocks() {
+		for i := iv.Start; i < iv.End; i++ {
+			d[i] |= LCBit
+		}
+	}
+	return d
+}
+
+// String satisfies fmt.Stringer.
+func (s DNA2) String() string {
+	return string(s.DNA8())
+}
+
+// Kmer returns the k bases starting at position i packed into a uint64,
+// with the last base in the least significant bits.
+//
+// Argument k must be at most 32.  N positions are included as their code,
+// 0.
+func (s DNA2) Kmer(i, k int) uint64 {
+	var x uint64
+	i += s.off
+	for end := i + k; i < end; {
+		if i&3 == 0 && end-i >= 4 {
+			x = x<<8 | uint64(s.b[i>>2])
+			i += 4
+			continue
+		}
+		x = x<<2 | uint64(s.b[i>>2]>>(6-2*uint(i&3))&3)
+		i++
+	}
+	return x
+}
+
+// pairs1 holds the number of nonzero 2-bit fields of a byte.
+var pairs1 [256]byte
+
+func init() {
+	for i := range pairs1 {
+		for b := i; b > 0; b >>= 2 {
+			if b&3 != 0 {
+				pairs1[i]++
+			}
+		}
+	}
+}
+
+// Hamming returns the hamming distance between s and t.
+//
+// N positions are compared by their code, 0, and so do not differ from A.
+// Sequences should be of equal length.  Panic or nonsense results if the
+// lengths are unequal.
+func (s DNA2) Hamming(t DNA2) (d int) {
+	i := 0
+	if s.off&3 == t.off&3 {
+		// compare whole bytes where possible
+		for ; i < s.n && (s.off+i)&3 != 0; i++ {
+			if s.Code(i) != t.Code(i) {
+				d++
+			}
+		}
+		sb := s.b[(s.off+i)>>2:]
+		tb := t.b[(t.off+i)>>2:]
+		for ; s.n-i >= 4; i += 4 {
+			d += int(pairs1[sb[0]^tb[0]])
+			sb = sb[1:]
+			tb = tb[1:]
+		}
+	}
+	for ; i < s.n; i++ {
+		if s.Code(i) != t.Code(i) {
+			d++
+		}
+	}
+	return
+}
+
+// ReverseComplement returns the reverse complement of the receiver.
+//
+// N and lower case masks are reversed as well.
+func (s DNA2) ReverseComplement() DNA2 {
+	rc := DNA2{b: make([]byte, (s.n+3)/4), n: s.n}
+	for i := 0; i < s.n; i++ {
+		j := s.n - 1 - i
+		// complement is code ^ 2
+		rc.b[j>>2] |= (s.Code(i) ^ 2) << (6 - 2*uint(j&3))
+	}
+	flip := func(b []Interval) []Interval {
+		r := make([]Interval, len(b))
+		for i, iv := range b {
+			r[len(b)-1-i] = Interval{s.n - iv.End, s.n - iv.Start}
+		}
+		return r
+	}
+	rc.nb = flip(s.NBlocks())
+	rc.lower = flip(s.LowerBlocks())
+	rc.clearN()
+	return rc
+}
+
+// clearN sets the code of N positions to 0.  The receiver must not be
+// a slice.
+func (s DNA2) clearN() {
+	for _, iv := range s.nb {
+		for i := iv.Start; i < iv.End; i++ {
+			s.b[i>>2] &^= 3 << (6 - 2*uint(i&3))
+		}
+	}
+}
+
+// packed returns packed data starting at a byte boundary.
+func (s DNA2) packed() []byte {
+	if s.off&3 == 0 {
+		return s.b[s.off>>2 : (s.off+s.n+3)>>2]
+	}
+	p := make([]byte, (s.n+3)/4)
+	for i := 0; i < s.n; i++ {
+		p[i>>2] |= s.Code(i) << (6 - 2*uint(i&3))
+	}
+	return p
+}
+
+// UCSC .2bit format constants and code conversion.  .2bit codes bases in
+// the order TCAG.
+const (
+	twoBitSig  = 0x1A412743
+	twoBitSwap = 0x4327411A
+)
+
+// twoBitConv converts a packed byte between TCAG and ACTG codes.
+// Per base, T 0 <-> 2, C 1 <-> 1, A 2 <-> 0, G 3 <-> 3.  The conversion
+// is its own inverse.
+var twoBitConv [256]byte
+
+func init() {
+	m := [4]byte{2, 1, 0, 3}
+	for i := range twoBitConv {
+		b := byte(i)
+		twoBitConv[i] = m[b>>6]<<6 | m[b>>4&3]<<4 | m[b>>2&3]<<2 | m[b&3]
+	}
+}
+
+// TwoBit provides access to sequences of a UCSC .2bit file.
+//
+// Sequences are read individually on demand so that only the sequences
+// needed are held in memory.
+type TwoBit struct {
+	r     io.ReaderAt
+	order binary.ByteOrder
+	Names []string         // sequence names in file order
+	off   map[string]int64 // file offset of sequence records
+}
+
+// OpenTwoBit reads the header and index of a .2bit file.
+//
+// Versions 0 and 1 of the format are supported, in either byte order.
+func OpenTwoBit(r io.ReaderAt) (*TwoBit, error) {
+	var h [16]byte
+	if _, err := r.ReadAt(h[:], 0); err != nil {
+		return nil, fmt.Errorf(".2bit: %v", err)
+	}
+	t := &TwoBit{r: r, off: map[string]int64{}}
+	switch binary.LittleEndian.Uint32(h[:]) {
+	case twoBitSig:
+		t.order = binary.LittleEndian
+	case twoBitSwap:
+		t.order = binary.BigEndian
+	default:
+		return nil, errors.New(".2bit: invalid signature")
+	}
+	version := t.order.Uint32(h[4:])
+	if version > 1 {
+		return nil, fmt.Errorf(".2bit: unsupported version %d", version)
+	}
+	count := int(t.order.Uint32(h[8:]))
+	br := bufio.NewReader(io.NewSectionReader(r, 16, 1<<62))
+	var b [8]byte
+	for i := 0; i < count; i++ {
+		ns, err := br.ReadByte()
+		if err != nil {
+			return nil, errors.New(".2bit: truncated index")
+		}
+		name := make([]byte, ns)
+		offSize := 4 + 4*int(version)
+		if _, err = io.ReadFull(br, name); err == nil {
+			_, err = io.ReadFull(br, b[:offSize])
+		}
+		if err != nil {
+			return nil, errors.New(".2bit: truncated index")
+		}
+		var off int64
+		if version == 0 {
+			off = int64(t.order.Uint32(b[:]))
+		} else {
+			off = int64(t.order.Uint64(b[:]))
+		}
+		t.Names = append(t.Names, string(name))
+		t.off[string(name)] = off
+	}
+	return t, nil
+}
+
+// Seq reads the named sequence.
+//
+// N positions are given code 0 regardless of the bases packed in the file.
+func (t *TwoBit) Seq(name string) (DNA2, error) {
+	off, ok := t.off[name]
+	if !ok {
+		return DNA2{}, fmt.Errorf(".2bit: sequence %s not found", name)
+	}
+	br := bufio.NewReader(io.NewSectionReader(t.r, off, 1<<62))
+	var err error
+	u32 := func() int {
+		var b [4]byte
+		if err == nil {
+			_, err = io.ReadFull(br, b[:])
+		}
+		return int(t.order.Uint32(b[:]))
+	}
+	blocks := func() []Interval {
+		c := u32()
+		if err != nil {
+			return nil
+		}
+		b := make([]Interval, c)
+		for i := range b {
+			b[i].Start = u32()
+		}
+		for i := range b {
+			b[i].End = b[i].Start + u32()
+		}
+		return b
+	}
+	s := DNA2{n: u32()}
+	s.nb = blocks()
+	s.lower = blocks()
+	u32() // reserved
+	if err == nil {
+		s.b = make([]byte, (s.n+3)/4)
+		_, err = io.ReadFull(br, s.b)
+	}
+	if err != nil {
+		return DNA2{}, fmt.Errorf(".2bit: sequence %s: %v", name, err)
+	}
+	for i, p := range s.b {
+		s.b[i] = twoBitConv[p]
+	}
+	// .2bit files may pack N as any base, typically T.  clear to code 0.
+	for _, iv := range s.nb {
+		if iv.End > s.n {
+			return DNA2{}, fmt.Errorf(".2bit: sequence %s: invalid N block",
+				name)
+		}
+	}
+	s.clearN()
+	return s, nil
+}
+
+// WriteTwoBit writes sequences as a .2bit file, version 0, little endian.
+//
+// Arguments names and seqs must be of the same length.
+func WriteTwoBit(w io.Writer, names []string, seqs []DNA2) error {
+	if len(names) != len(seqs) {
+		return errors.New(".2bit: number of names and sequences differ")
+	}
+	bw := bufio.NewWriter(w)
+	le := binary.LittleEndian
+	u32 := func(x int) {
+		var b [4]byte
+		le.PutUint32(b[:], uint32(x))
+		bw.Write(b[:])
+	}
+	u32(twoBitSig)
+	u32(0)
+	u32(len(names))
+	u32(0)
+	// index
+	off := int64(16)
+	for _, n := range names {
+		if len(n) > 255 {
+			return fmt.Errorf(".2bit: name too long: %s", n)
+		}
+		off += int64(1 + len(n) + 4)
+	}
+	nb := make([][]Interval, len(seqs))
+	lb := make([][]Interval, len(seqs))
+	for i, n := range names {
+		if off > 1<<32-1 {
+			return errors.New(".2bit: data too large for version 0")
+		}
+		bw.WriteByte(byte(len(n)))
+		bw.WriteString(n)
+		u32(int(off))
+		s := seqs[i]
+		nb[i] = s.NBlocks()
+		lb[i] = s.LowerBlocks()
+		off += int64(16 + 8*len(nb[i]) + 8*len(lb[i]) + (s.n+3)/4)
+	}
+	// records
+	for i, s := range seqs {
+		u32(s.n)
+		for _, b := range [][]Interval{nb[i], lb[i]} {
+			u32(len(b))
+			for _, iv := range b {
+				u32(iv.Start)
+			}
+			for _, iv := range b {
+				u32(iv.End - iv.Start)
+			}
+		}
+		u32(0)
+		for _, p := range s.packed() {
+			bw.WriteByte(twoBitConv[p])
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,174 @@
+package bio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleNewDNA2() {
+	s := bio.NewDNA2([]byte("ACGTNNacgtnAC"))
+	fmt.Println(s.Len(), s)
+	fmt.Println(s.NBlocks(), s.LowerBlocks())
+	t := s.Slice(3, 9)
+	fmt.Println(t, t.NBlocks(), t.LowerBlocks())
+	fmt.Println(t.ReverseComplement())
+	// Output:
+	// 13 ACGTNNacgtnAC
+	// [{4 6} {10 11}] [{6 11}]
+	// TNNacg [{1 3}] [{3 6}]
+	// cgtNNA
+}
+
+func ExampleDNA2_Kmer() {
+	s := bio.DNA8("GATTACA").DNA2()
+	fmt.Printf("%014b\n", s.Kmer(0, 7))
+	fmt.Printf("%06b\n", s.Kmer(4, 3))
+	// Output:
+	// 11001010000100
+	// 000100
+}
+
+func randDNA8(n int) bio.DNA8 {
+	s := make(bio.DNA8, n)
+	for i := range s {
+		s[i] = "ACTG"[rand.Intn(4)]
+	}
+	return s
+}
+
+func TestDNA2(t *testing.T) {
+	for n := 0; n < 100; n++ {
+		s := randDNA8(50 + rand.Intn(50))
+		u := randDNA8(len(s))
+		for i := range u {
+			if rand.Intn(3) > 0 {
+				u[i] = s[i]
+			}
+		}
+		i := rand.Intn(20)
+		j := len(s) - rand.Intn(20)
+		ps := s.DNA2().Slice(i, j)
+		// vary alignment of u relative to s
+		pre := randDNA8(rand.Intn(5))
+		pu := append(pre, u...).DNA2().Slice(len(pre)+i, len(pre)+j)
+		if got := ps.DNA8(); !bytes.Equal(got, s[i:j]) {
+			t.Fatalf("DNA8 = %s, want %s", got, s[i:j])
+		}
+		if got, want := ps.Hamming(pu), s[i:j].Hamming(u[i:j]); got != want {
+			t.Fatalf("Hamming = %d, want %d", got, want)
+		}
+		if h := ps.Hamming(ps.Slice(0, ps.Len())); h != 0 {
+			t.Fatalf("Hamming with self = %d", h)
+		}
+		if got, want := ps.ReverseComplement().DNA8(),
+			s[i:j].ReverseComplement(); !bytes.Equal(got, want) {
+			t.Fatalf("ReverseComplement = %s, want %s", got, want)
+		}
+		k := 1 + rand.Intn(32)
+		var want uint64
+		for _, b := range s[i : i+k] {
+			want = want<<2 | uint64(b>>1&3)
+		}
+		if got := ps.Kmer(0, k); got != want {
+			t.Fatalf("Kmer = %x, want %x", got, want)
+		}
+	}
+}
+
+func TestTwoBit(t *testing.T) {
+	names := []string{"chr1", "chrM"}
+	seqs := []bio.DNA2{
+		bio.NewDNA2([]byte("ACGTNNNNacgtACGTA")),
+		bio.NewDNA2([]byte("acgTTGCAAnGT")).Slice(1, 11),
+	}
+	var b bytes.Buffer
+	if err := bio.WriteTwoBit(&b, names, seqs); err != nil {
+		t.Fatal(err)
+	}
+	// first bases of chr1 in TCAG coding
+	if p := bytes.Index(b.Bytes(), []byte{0x9c, 0x9c}); p < 0 {
+		t.Fatal("packed data not found")
+	}
+	tb, err := bio.OpenTwoBit(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tb.Names, names) {
+		t.Fatal("names", tb.Names)
+	}
+	for i, n := range names {
+		s, err := tb.Seq(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.String() != seqs[i].String() {
+			t.Fatalf("%s = %s, want %s", n, s, seqs[i])
+		}
+	}
+}
+
+func TestDNA2ReverseComplementN(t *testing.T) {
+	s := bio.NewDNA2([]byte("ACnNNGTNAC")).Slice(1, 9)
+	rc := s.ReverseComplement()
+	if got := rc.String(); got != "TNACNNnG" {
+		t.Fatalf("ReverseComplement = %s", got)
+	}
+	for _, iv := range rc.NBlocks() {
+		for i := iv.Start; i < iv.End; i++ {
+			if c := rc.Code(i); c != 0 {
+				t.Fatalf("Code(%d) = %d, want 0", i, c)
+			}
+		}
+	}
+	if h := rc.Hamming(bio.NewDNA2([]byte(rc.String()))); h != 0 {
+		t.Fatalf("Hamming with round trip = %d", h)
+	}
+}
+
+// TestTwoBitN reads a file with N packed as T, as written by faToTwoBit.
+func TestTwoBitN(t *testing.T) {
+	var w bytes.Buffer
+	u32 := func(x ...uint32) { binary.Write(&w, binary.LittleEndian, x) }
+	u32(0x1A412743, 0, 1, 0)
+	w.WriteString("\x04chr1")
+	u32(25)
+	// 8 bases, N block 2:6, no mask blocks, reserved
+	u32(8, 1, 2, 4, 0, 0)
+	// ACNNNNGT in TCAG coding with N as T
+	w.Write([]byte{0x90, 0x0C})
+	b := w.Bytes()
+	tb, err := bio.OpenTwoBit(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := tb.Seq("chr1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bio.NewDNA2([]byte("ACNNNNGT"))
+	if s.String() != want.String() {
+		t.Fatalf("got %s, want %s", s, want)
+	}
+	if d := s.Hamming(want); d != 0 {
+		t.Fatal("Hamming", d)
+	}
+	if k, w := s.Kmer(0, 8), want.Kmer(0, 8); k != w {
+		t.Fatalf("Kmer %d, want %d", k, w)
+	}
+	for i := 0; i < 8; i++ {
+		if c, w := s.Code(i), want.Code(i); c != w {
+			t.Fatalf("Code(%d) = %d, want %d", i, c, w)
+		}
+	}
+	// N block past the end of the sequence
+	b[len(b)-14] = 7
+	if _, err := tb.Seq("chr1"); err == nil {
+		t.Fatal("no error for invalid N block")
+	}
+}
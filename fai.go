@@ -0,0 +1,261 @@
+package bio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// FAIRecord is a single line of a FASTA index as used by samtools faidx.
+type FAIRecord struct {
+	Name      string // sequence identifier, as returned by FASTASeq.ID
+	Length    int    // number of symbols in the sequence
+	Offset    int64  // file offset of the first symbol of the sequence
+	LineBases int    // number of symbols per line
+	LineWidth int    // number of bytes per line, including line terminator
+}
+
+// BuildFAI reads FASTA from an io.Reader and constructs a FASTA index.
+//
+// The FASTA must be uniformly wrapped.  That is, within each sequence
+// all lines must have the same length except the last line, which may
+// be shorter.  Line terminators may be lf or crlf but must be consistent
+// within a sequence.  Blank lines are allowed only at the end of
+// a sequence.  Sequence names, as returned by FASTASeq.ID, must be unique.
+func BuildFAI(r io.Reader) (idx []FAIRecord, err error) {
+	br := bufio.NewReader(r)
+	var off int64 // file offset of current line
+	var rec *FAIRecord
+	names := map[string]bool{}
+	short := false // a short line has been seen in rec
+	blank := false // a blank line has been seen in rec
+	for {
+		width, term, header, err := faiLine(br)
+		switch {
+		case err != nil:
+			return nil, err
+		case width == 0:
+			return idx, nil // EOF
+		}
+		n := width - term // number of symbols on line
+		switch {
+		case header != nil:
+			name := headerID(string(header))
+			if names[name] {
+				return nil, fmt.Errorf(
+					"FASTA index: duplicate sequence name %s", name)
+			}
+			names[name] = true
+			idx = append(idx, FAIRecord{
+				Name:   name,
+				Offset: off + int64(width),
+			})
+			rec = &idx[len(idx)-1]
+			short = false
+			blank = false
+		case rec == nil:
+			return nil, errors.New("FASTA index: no header")
+		case n == 0:
+			blank = true
+		case blank:
+			return nil, fmt.Errorf(
+				"FASTA index: blank line within sequence %s", rec.Name)
+		case rec.LineBases == 0:
+			rec.LineBases = n
+			rec.LineWidth = width
+			rec.Length = n
+		case short || n > rec.LineBases ||
+			term > 0 && term != rec.LineWidth-rec.LineBases:
+			return nil, fmt.Errorf(
+				"FASTA index: inconsistent line length in sequence %s", rec.Name)
+		default:
+			short = n < rec.LineBases
+			rec.Length += n
+		}
+		off += int64(width)
+	}
+}
+
+// faiLine reads a line of unlimited length.  It returns the length of the
+// line in bytes including any terminator, the length of the terminator,
+// and if the line is a header, the header without terminator.
+func faiLine(br *bufio.Reader) (width, term int, header []byte, err error) {
+	var tail [2]byte // last two bytes of line
+	for {
+		l, e := br.ReadSlice('\n')
+		if width == 0 && len(l) > 0 && l[0] == '>' {
+			header = []byte{}
+		}
+		if header != nil {
+			header = append(header, l...)
+		}
+		switch len(l) {
+		case 0:
+		case 1:
+			tail[0], tail[1] = tail[1], l[0]
+		default:
+			tail[0], tail[1] = l[len(l)-2], l[len(l)-1]
+		}
+		width += len(l)
+		if e == bufio.ErrBufferFull {
+			continue
+		}
+		if e != nil && e != io.EOF {
+			return 0, 0, nil, e
+		}
+		break
+	}
+	if width > 0 && tail[1] == '\n' {
+		term = 1
+		if width > 1 && tail[0] == '\r' {
+			term = 2
+		}
+	}
+	if header != nil {
+		header = header[:len(header)-term]
+	}
+	return
+}
+
+// ReadFAI reads a FASTA index in the tab separated format of samtools faidx.
+//
+// Only the first five columns are used.
+func ReadFAI(r io.Reader) (idx []FAIRecord, err error) {
+	br := bufio.NewReader(r)
+	names := map[string]bool{}
+	for ln := 1; ; ln++ {
+		line, err := readLine(br)
+		if err == io.EOF {
+			return idx, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 {
+			continue
+		}
+		f := strings.Split(string(line), "\t")
+		if len(f) < 5 {
+			return nil, fmt.Errorf("FASTA index line %d: %d fields", ln, len(f))
+		}
+		var rec FAIRecord
+		rec.Name = f[0]
+		rec.Length, err = strconv.Atoi(f[1])
+		if err == nil {
+			rec.Offset, err = strconv.ParseInt(f[2], 10, 64)
+		}
+		if err == nil {
+			rec.LineBases, err = strconv.Atoi(f[3])
+		}
+		if err == nil {
+			rec.LineWidth, err = strconv.Atoi(f[4])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("FASTA index line %d: %v", ln, err)
+		}
+		if names[rec.Name] {
+			return nil, fmt.Errorf("FASTA index line %d: duplicate name %s",
+				ln, rec.Name)
+		}
+		names[rec.Name] = true
+		idx = append(idx, rec)
+	}
+}
+
+// WriteFAI writes a FASTA index in the tab separated format of
+// samtools faidx.
+func WriteFAI(w io.Writer, idx []FAIRecord) error {
+	bw := bufio.NewWriter(w)
+	for _, rec := range idx {
+		fmt.Fprintf(bw, "%s\t%d\t%d\t%d\t%d\n",
+			rec.Name, rec.Length, rec.Offset, rec.LineBases, rec.LineWidth)
+	}
+	return bw.Flush()
+}
+
+// IndexedFASTA provides random access to sequences of a FASTA file.
+type IndexedFASTA struct {
+	r     io.ReaderAt
+	Index []FAIRecord
+	names map[string]int // index into Index
+}
+
+// NewIndexedFASTA constructs an IndexedFASTA from an io.ReaderAt of
+// FASTA data and an index of the data, as returned by BuildFAI or ReadFAI.
+func NewIndexedFASTA(r io.ReaderAt, idx []FAIRecord) *IndexedFASTA {
+	x := &IndexedFASTA{r: r, Index: idx, names: map[string]int{}}
+	for i, rec := range idx {
+		x.names[rec.Name] = i
+	}
+	return x
+}
+
+// Names returns the names of all sequences in the index, in file order.
+func (x *IndexedFASTA) Names() []string {
+	n := make([]string, len(x.Index))
+	for i, rec := range x.Index {
+		n[i] = rec.Name
+	}
+	return n
+}
+
+// Len returns the length of the named sequence.
+//
+// Result ok is false if the sequence is not in the index.
+func (x *IndexedFASTA) Len(name string) (n int, ok bool) {
+	i, ok := x.names[name]
+	if !ok {
+		return 0, false
+	}
+	return x.Index[i].Length, true
+}
+
+// Seq returns the subsequence start:end of the named sequence.
+//
+// Arguments start and end are 0-based and the range is half-open, as with
+// Go slice expressions.  Only the file data for the requested range is read.
+func (x *IndexedFASTA) Seq(name string, start, end int) (Seq, error) {
+	i, ok := x.names[name]
+	if !ok {
+		return nil, fmt.Errorf("FASTA index: sequence %s not found", name)
+	}
+	rec := &x.Index[i]
+	if start < 0 || end > rec.Length || start > end {
+		return nil, fmt.Errorf("FASTA index: range %d:%d out of bounds for %s",
+			start, end, name)
+	}
+	if start == end {
+		return Seq{}, nil
+	}
+	fo := func(p int) int64 {
+		return rec.Offset + int64(p/rec.LineBases*rec.LineWidth+p%rec.LineBases)
+	}
+	o0 := fo(start)
+	b := make([]byte, fo(end-1)+1-o0)
+	if n, err := x.r.ReadAt(b, o0); err != nil && !(err == io.EOF && n == len(b)) {
+		return nil, err
+	}
+	// remove line terminators
+	s := b[:0]
+	for _, c := range b {
+		if c != '\n' && c != '\r' {
+			s = append(s, c)
+		}
+	}
+	return Seq(s), nil
+}
+
+// ReverseComplement returns the reverse complement of the subsequence
+// start:end of the named sequence.
+//
+// Arguments are as for Seq.  The sequence data should be DNA8.
+func (x *IndexedFASTA) ReverseComplement(name string, start, end int) (DNA8, error) {
+	s, err := x.Seq(name, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return DNA8(s).ReverseComplement(), nil
+}
@@ -0,0 +1,85 @@
+package bio_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/soniakeys/bio"
+)
+
+const faiTestFASTA = `>chr1 first
+ACGTACGTAC
+GTTTTTGGGG
+CCA
+>chr2
+AAAACCCCGG
+GGTTTTAC
+`
+
+func ExampleBuildFAI() {
+	idx, err := bio.BuildFAI(strings.NewReader(faiTestFASTA))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	bio.WriteFAI(os.Stdout, idx)
+	// Output:
+	// chr1	23	12	10	11
+	// chr2	18	44	10	11
+}
+
+func ExampleReadFAI() {
+	idx, err := bio.ReadFAI(strings.NewReader("chr1\t23\t12\t10\t11\n"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%+v\n", idx[0])
+	// Output:
+	// {Name:chr1 Length:23 Offset:12 LineBases:10 LineWidth:11}
+}
+
+func ExampleBuildFAI_inconsistent() {
+	_, err := bio.BuildFAI(strings.NewReader(">s\nACGT\nAC\nACGT\n"))
+	fmt.Println(err)
+	// Output:
+	// FASTA index: inconsistent line length in sequence s
+}
+
+func ExampleBuildFAI_names() {
+	idx, err := bio.BuildFAI(strings.NewReader(">chr1\tfirst\nACGT\n"))
+	fmt.Println(idx[0].Name, err)
+	_, err = bio.BuildFAI(strings.NewReader(">s\nACGT\n>s x\nAC\n"))
+	fmt.Println(err)
+	_, err = bio.ReadFAI(strings.NewReader("s\t4\t3\t4\t5\ns\t2\t11\t2\t3\n"))
+	fmt.Println(err)
+	// Output:
+	// chr1 <nil>
+	// FASTA index: duplicate sequence name s
+	// FASTA index line 2: duplicate name s
+}
+
+func ExampleIndexedFASTA_Seq() {
+	r := strings.NewReader(faiTestFASTA)
+	idx, err := bio.BuildFAI(r)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	x := bio.NewIndexedFASTA(r, idx)
+	fmt.Println(x.Names())
+	fmt.Println(x.Len("chr2"))
+	fmt.Println(x.Seq("chr1", 8, 21))
+	fmt.Println(x.Seq("chr2", 0, 18))
+	fmt.Println(x.ReverseComplement("chr1", 8, 21))
+	_, err = x.Seq("chr3", 0, 1)
+	fmt.Println(err)
+	// Output:
+	// [chr1 chr2]
+	// 18 true
+	// ACGTTTTTGGGGC <nil>
+	// AAAACCCCGGGGTTTTAC <nil>
+	// GCCCCAAAAACGT <nil>
+	// FASTA index: sequence chr3 not found
+}
@@ -3,16 +3,11 @@ package bio
 import (
 	"bufio"
 	"bytes"
-	"errors"
+	"fmt"
 	"io"
-	"io/ioutil"
-	"os"
 	"strings"
 )
 
-// This file currently has two separate readers.  They both return FASTASeq
-// results at this point, But need further integration.  (TODO)
-
 // FASTASeq is a sequence annotated with a header.
 type FASTASeq struct {
 	Header string // complete header line, including '>'
@@ -21,122 +16,311 @@ type FASTASeq struct {
 
 // ID extracts the sequence identifier from the header.
 func (f FASTASeq) ID() string {
-	if f.Header == "" {
+	return headerID(f.Header)
+}
+
+// Desc extracts the description from the header.
+func (f FASTASeq) Desc() string {
+	return headerDesc(f.Header)
+}
+
+// headerID extracts the identifier from a FASTA or FASTQ header line.
+// The first byte of the header, '>' or '@', is skipped.  The identifier
+// ends at the first space or tab.
+func headerID(h string) string {
+	if h == "" {
 		return ""
 	}
-	id := f.Header[1:]
-	if sp := strings.IndexByte(id, ' '); sp >= 0 {
+	id := h[1:]
+	if sp := strings.IndexAny(id, " \t"); sp >= 0 {
 		return id[:sp]
 	}
 	return id
 }
 
-// Desc extracts the description from the header.
-func (f FASTASeq) Desc() string {
-	if f.Header == "" {
+// headerDesc extracts the description from a FASTA or FASTQ header line.
+func headerDesc(h string) string {
+	if h == "" {
 		return ""
 	}
-	id := f.Header[1:]
-	if sp := strings.IndexByte(id, ' '); sp >= 0 {
+	id := h[1:]
+	if sp := strings.IndexAny(id, " \t"); sp >= 0 {
 		return id[sp+1:]
 	}
 	return ""
 }
 
-// ReadFASTA reads FASTA format from an io.Reader
-//
-// Super minimal whole-file reader.  It allows blank lines,
-// it trims leading and trailing white space, and it
-// allows both lf and crlf line endings.  No other frills.
-// Headers are stored unparsed.
+// FASTAOptions controls the strictness of FASTA parsing.
 //
-// The first non-blank line must be a header.  A FASTASeq is returned
-// for every header line, even if there is no data following the header.
-func ReadFASTA(r io.Reader) (seq []FASTASeq, err error) {
-	d, err := ioutil.ReadAll(r)
-	if err != nil {
-		return nil, err
-	}
-	var last *FASTASeq
-	foundHeader := false
-	for _, l := range bytes.Split(d, []byte{'\n'}) {
-		l = bytes.TrimSpace(l)
-		switch {
-		case len(l) == 0:
-			continue // skip blank line
-		case l[0] == '>':
-			foundHeader = true // new seq
-			seq = append(seq, FASTASeq{string(l), nil})
-			last = &seq[len(seq)-1]
-			continue
-		case !foundHeader:
-			return nil, errors.New("No header")
-		}
-		last.Seq = append(last.Seq, l...) // store seq data
-	}
-	return seq, nil
+// The zero value is strict:  The first non-blank line must be a header,
+// lines starting with ';' are taken as sequence data, symbols are not
+// validated, and case is preserved.
+type FASTAOptions struct {
+	// SkipPreamble ignores any text before the first header rather than
+	// returning an error.
+	SkipPreamble bool
+	// Comments ignores lines starting with ';'.
+	Comments bool
+	// Alphabet, if non-nil, lists allowed sequence symbols.  Symbols are
+	// validated after case folding.
+	Alphabet []byte
+	// Fold is FoldNone, FoldUpper or FoldLower.
+	Fold int
 }
 
-// ReadFASTAFile is a high level function that reads an entire FASTA file
-// into memory.  It calls the ReadFASTA function.
-func ReadFASTAFile(path string) ([]FASTASeq, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-	return ReadFASTA(f)
+// Case folding options for FASTAOptions.
+const (
+	FoldNone  = iota // preserve case
+	FoldUpper        // convert sequence symbols to upper case
+	FoldLower        // convert sequence symbols to lower case
+)
+
+// FASTAError describes a problem parsing FASTA input.
+type FASTAError struct {
+	Record int    // 0-based index of the record being parsed
+	Line   int    // 1-based line number
+	Msg    string // description of the problem
 }
 
-// second reader follows
+func (e *FASTAError) Error() string {
+	return fmt.Sprintf("FASTA record %d, line %d: %s", e.Record, e.Line, e.Msg)
+}
 
 // FASTAReader type for representing a FASTA stream.
+//
+// Input is read line by line and a single record is held in memory at
+// a time.  Blank lines are ignored and leading and trailing white space
+// is trimmed from all lines.  Both lf and crlf line endings are allowed.
+// Headers are stored unparsed.  There is no limit on line length.
 type FASTAReader struct {
 	r          *bufio.Reader
+	opt        FASTAOptions
+	alpha      *[256]bool // nil if no alphabet
 	nextHeader string
+	line       int // number of lines read
+	rec        int // number of records returned
 }
 
 // NewFASTAReader constructs and returns a FASTAReader around an io.Reader.
+//
+// Options are the zero value FASTAOptions except that SkipPreamble is true.
 func NewFASTAReader(r io.Reader) FASTAReader {
-	return FASTAReader{r: bufio.NewReader(r)}
+	return NewFASTAReaderOptions(r, FASTAOptions{SkipPreamble: true})
+}
+
+// NewFASTAReaderOptions constructs a FASTAReader with the given options.
+func NewFASTAReaderOptions(r io.Reader, opt FASTAOptions) FASTAReader {
+	fr := FASTAReader{r: bufio.NewReader(r), opt: opt}
+	if opt.Alphabet != nil {
+		fr.alpha = new([256]bool)
+		for _, b := range opt.Alphabet {
+			fr.alpha[b] = true
+		}
+	}
+	return fr
+}
+
+func (r *FASTAReader) error(msg string) error {
+	return &FASTAError{r.rec, r.line, msg}
 }
 
 // ReadSeq returns a single sequence on each call.
 //
+// A FASTASeq is returned for every header line, even if there is no data
+// following the header.
+//
 // A successful read is indicated by err = nil for all sequences, including
-// the last.  Subsequent calls return err = io.EOF.
-// Other error values indicate problems.
+// the last.  Subsequent calls return err = io.EOF.  Parsing problems are
+// returned as *FASTAError.  Other error values indicate I/O problems.
 func (r *FASTAReader) ReadSeq() (FASTASeq, error) {
 	f := FASTASeq{r.nextHeader, nil}
 	r.nextHeader = ""
-	var line []byte
-	var isPre bool
-	var err error
-read:
 	for {
-		line, isPre, err = r.r.ReadLine()
-		switch {
-		case err != nil:
+		line, err := readLine(r.r)
+		if err != nil {
 			if err == io.EOF && f.Header > "" {
+				r.rec++
 				err = nil
 			}
-			break read
-		case isPre:
-			err = errors.New("FASTA line too long")
-			break read
-		case len(line) == 0: // ignore blank lines
-			continue
-		case line[0] == '>': // info line
+			return f, err
+		}
+		r.line++
+		line = bytes.TrimSpace(line)
+		switch {
+		case len(line) == 0:
+			continue // skip blank line
+		case line[0] == '>': // new seq
 			if f.Header > "" {
 				r.nextHeader = string(line)
-				break read
+				r.rec++
+				return f, nil
 			}
 			f.Header = string(line)
-		case f.Header > "":
-			f.Seq = append(f.Seq, line...)
-		default:
-			// ignore initial data without Header?
+			continue
+		case line[0] == ';' && r.opt.Comments:
+			continue
+		case f.Header == "":
+			if r.opt.SkipPreamble {
+				continue
+			}
+			return f, r.error("no header")
+		}
+		// store seq data
+		start := len(f.Seq)
+		f.Seq = append(f.Seq, line...)
+		switch r.opt.Fold {
+		case FoldUpper:
+			for i, b := range f.Seq[start:] {
+				if b >= 'a' && b <= 'z' {
+					f.Seq[start+i] = b &^ LCBit
+				}
+			}
+		case FoldLower:
+			for i, b := range f.Seq[start:] {
+				if b >= 'A' && b <= 'Z' {
+					f.Seq[start+i] = b | LCBit
+				}
+			}
+		}
+		if r.alpha != nil {
+			for _, b := range f.Seq[start:] {
+				if !r.alpha[b] {
+					return f, r.error(fmt.Sprintf("invalid symbol %q", b))
+				}
+			}
+		}
+	}
+}
+
+// readLine reads a complete line, regardless of length.
+// The line terminator is not included.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, isPre, err := r.ReadLine()
+	if err != nil || !isPre {
+		return line, err
+	}
+	l := append([]byte{}, line...)
+	for isPre {
+		if line, isPre, err = r.ReadLine(); err != nil {
+			break
+		}
+		l = append(l, line...)
+	}
+	return l, err
+}
+
+// ReadFASTA reads FASTA format from an io.Reader
+//
+// It reads all sequences with a FASTAReader using strict options,
+// the zero value of FASTAOptions.  That is, the first non-blank line
+// must be a header.
+func ReadFASTA(r io.Reader) ([]FASTASeq, error) {
+	return ReadFASTAOptions(r, FASTAOptions{})
+}
+
+// ReadFASTAOptions reads all sequences from an io.Reader using
+// the given options.
+func ReadFASTAOptions(r io.Reader, opt FASTAOptions) (seq []FASTASeq, err error) {
+	fr := NewFASTAReaderOptions(r, opt)
+	for {
+		f, err := fr.ReadSeq()
+		switch {
+		case err == io.EOF:
+			return seq, nil
+		case err != nil:
+			return nil, err
+		}
+		seq = append(seq, f)
+	}
+}
+
+// ReadFASTAFile is a high level function that reads an entire FASTA file
+// into memory.  It calls the ReadFASTA function.
+//
+// Gzip or BGZF compressed files are decompressed transparently.
+func ReadFASTAFile(path string) ([]FASTASeq, error) {
+	f, err := OpenSeqFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadFASTA(f)
+}
+
+// FASTAHeader constructs a FASTA header line from a sequence identifier
+// and an optional description.
+func FASTAHeader(id, desc string) string {
+	if desc == "" {
+		return ">" + id
+	}
+	return ">" + id + " " + desc
+}
+
+// FASTAWriter writes FASTA records to an underlying io.Writer.
+//
+// Width is the maximum number of sequence symbols written per line.
+// NewFASTAWriter initializes it to 60.  A Width of 0 writes each sequence
+// on a single line.
+//
+// Output is buffered.  Call Flush after the last record is written.
+type FASTAWriter struct {
+	w     *bufio.Writer
+	Width int
+}
+
+// NewFASTAWriter constructs a FASTAWriter around an io.Writer.
+func NewFASTAWriter(w io.Writer) *FASTAWriter {
+	return &FASTAWriter{w: bufio.NewWriter(w), Width: 60}
+}
+
+// WriteRecord writes a single sequence with a header constructed from
+// id and desc.
+//
+// Argument s can be a Seq, DNA8, AA20, or any other byte sequence type.
+// An empty sequence is written as a header line only.
+func (w *FASTAWriter) WriteRecord(id, desc string, s []byte) error {
+	w.w.WriteString(FASTAHeader(id, desc))
+	w.w.WriteByte('\n')
+	width := w.Width
+	if width <= 0 {
+		width = len(s)
+	}
+	for len(s) > 0 {
+		n := width
+		if n > len(s) {
+			n = len(s)
+		}
+		w.w.Write(s[:n])
+		if err := w.w.WriteByte('\n'); err != nil {
+			return err
+		}
+		s = s[n:]
+	}
+	return nil
+}
+
+// WriteSeq writes a FASTASeq.
+//
+// The header is reconstructed from f.ID() and f.Desc().
+func (w *FASTAWriter) WriteSeq(f FASTASeq) error {
+	return w.WriteRecord(f.ID(), f.Desc(), f.Seq)
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *FASTAWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// WriteFASTA writes a list of sequences in FASTA format.
+//
+// Argument width is the sequence line length as described for FASTAWriter.
+func WriteFASTA(w io.Writer, seq []FASTASeq, width int) error {
+	fw := NewFASTAWriter(w)
+	fw.Width = width
+	for _, f := range seq {
+		if err := fw.WriteSeq(f); err != nil {
+			return err
 		}
 	}
-	return f, err
+	return fw.Flush()
 }
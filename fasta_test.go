@@ -3,6 +3,9 @@ package bio_test
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"strings"
+	"testing"
 
 	"github.com/soniakeys/bio"
 )
@@ -62,3 +65,91 @@ TACCA`)
 	// Seq:    AGACCATACCA
 	// EOF
 }
+
+func ExampleFASTAWriter() {
+	w := bio.NewFASTAWriter(os.Stdout)
+	w.Width = 10
+	w.WriteRecord("seq1", "a DNA8", bio.DNA8("ACGTACGTACGTACGTACGTACG"))
+	w.WriteRecord("pep1", "", bio.AA20("MANNY"))
+	w.Width = 0
+	w.WriteSeq(bio.FASTASeq{Header: ">seq2", Seq: bio.Seq("ACGTACGTACGTACGT")})
+	w.Flush()
+	// Output:
+	// >seq1 a DNA8
+	// ACGTACGTAC
+	// GTACGTACGT
+	// ACG
+	// >pep1
+	// MANNY
+	// >seq2
+	// ACGTACGTACGTACGT
+}
+
+func TestWriteFASTA(t *testing.T) {
+	// round trip of well formed input is byte-identical
+	in := `>db|123|abc example sequence
+AGACCATACCAGACCATACCAGACCATACCAGACCATACCAGACCATACCAGACCATACC
+AGACCATACCAGACCATA
+>db|124|abd
+>db|125|abe another example
+TACCA
+`
+	s, err := bio.ReadFASTA(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := bio.WriteFASTA(&b, s, 60); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != in {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, in)
+	}
+}
+
+func ExampleNewFASTAReaderOptions() {
+	b := bytes.NewBufferString(`preamble text
+>s1 lenient example
+;a comment
+acgt
+ACGT
+`)
+	r := bio.NewFASTAReaderOptions(b, bio.FASTAOptions{
+		SkipPreamble: true,
+		Comments:     true,
+		Alphabet:     []byte("ACGT"),
+		Fold:         bio.FoldUpper,
+	})
+	s, err := r.ReadSeq()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(s.Header)
+	fmt.Println(s.Seq)
+	// Output:
+	// >s1 lenient example
+	// ACGTACGT
+}
+
+func ExampleFASTAError() {
+	b := bytes.NewBufferString(`>s1
+ACGT
+>s2
+ACGT
+ACXT
+`)
+	_, err := bio.ReadFASTAOptions(b, bio.FASTAOptions{
+		Alphabet: []byte("ACGT"),
+	})
+	if e, ok := err.(*bio.FASTAError); ok {
+		fmt.Println("record", e.Record, "line", e.Line)
+	}
+	fmt.Println(err)
+	_, err = bio.ReadFASTA(bytes.NewBufferString("ACGT\n>s1\nACGT\n"))
+	fmt.Println(err)
+	// Output:
+	// record 1 line 5
+	// FASTA record 1, line 5: invalid symbol 'X'
+	// FASTA record 0, line 1: no header
+}
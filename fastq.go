@@ -0,0 +1,302 @@
+package bio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+)
+
+// FASTQSeq is a sequence read annotated with a header and quality scores.
+type FASTQSeq struct {
+	Header string // complete header line, including '@'
+	Seq           // sequence, all lines concatenated
+	Qual   []byte // decoded Phred quality scores, one for each symbol of Seq
+}
+
+// ID extracts the sequence identifier from the header.
+func (f FASTQSeq) ID() string {
+	return headerID(f.Header)
+}
+
+// Desc extracts the description from the header.
+func (f FASTQSeq) Desc() string {
+	return headerDesc(f.Header)
+}
+
+// Offsets for ASCII encoding of Phred quality scores.
+const (
+	Phred33 = 33 // Sanger and Illumina 1.8+
+	Phred64 = 64 // Illumina 1.3 to 1.7
+)
+
+// DetectPhredOffset examines encoded quality symbols and returns the
+// likely encoding offset, either Phred33 or Phred64.
+//
+// Symbols below '@' can only be Phred33.  Symbols above 'J' are beyond
+// the range normally produced by Phred33 instruments and indicate Phred64.
+// If neither is seen, 0 is returned.
+func DetectPhredOffset(q []byte) int {
+	hi := false
+	for _, b := range q {
+		switch {
+		case b < Phred64:
+			return Phred33
+		case b > 'J':
+			hi = true
+		}
+	}
+	if hi {
+		return Phred64
+	}
+	return 0
+}
+
+// FASTQReader type for representing a FASTQ stream.
+//
+// Offset is the Phred offset used to decode quality symbols.  The value
+// returned by NewFASTQReader is 0, meaning the offset is detected from
+// the first record read with DetectPhredOffset.  If detection is
+// inconclusive, Phred33 is assumed.  Set Offset before the first call to
+// ReadSeq to override detection.
+type FASTQReader struct {
+	r      *bufio.Reader
+	line   int // number of lines read
+	Offset int
+}
+
+// NewFASTQReader constructs and returns a FASTQReader around an io.Reader.
+func NewFASTQReader(r io.Reader) FASTQReader {
+	return FASTQReader{r: bufio.NewReader(r)}
+}
+
+func (r *FASTQReader) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("FASTQ line %d: "+format, append([]interface{}{r.line}, a...)...)
+}
+
+// ReadSeq returns a single sequence on each call.
+//
+// Sequence and quality data may each span multiple lines.  Quality lines
+// are read until there are as many quality symbols as sequence symbols.
+// Blank lines between records are ignored.
+//
+// A successful read is indicated by err = nil for all sequences, including
+// the last.  Subsequent calls return err = io.EOF.  Other error values
+// indicate malformed records and include the line number where the
+// problem was found.
+func (r *FASTQReader) ReadSeq() (FASTQSeq, error) {
+	var f FASTQSeq
+	var line []byte
+	var err error
+	// header
+	for {
+		if line, err = readLine(r.r); err != nil {
+			return f, err
+		}
+		r.line++
+		if len(line) > 0 {
+			break
+		}
+	}
+	if line[0] != '@' {
+		return f, r.errorf("expected '@' header line")
+	}
+	f.Header = string(line)
+	// sequence
+	for {
+		if line, err = readLine(r.r); err != nil {
+			if err == io.EOF {
+				err = r.errorf("unexpected EOF in sequence")
+			}
+			return f, err
+		}
+		r.line++
+		if len(line) > 0 && line[0] == '+' {
+			break
+		}
+		f.Seq = append(f.Seq, line...)
+	}
+	// quality
+	for len(f.Qual) < len(f.Seq) {
+		if line, err = readLine(r.r); err != nil {
+			if err == io.EOF {
+				err = r.errorf("unexpected EOF in quality")
+			}
+			return f, err
+		}
+		r.line++
+		f.Qual = append(f.Qual, line...)
+	}
+	if len(f.Qual) != len(f.Seq) {
+		return f, r.errorf("%d quality symbols for %d sequence symbols",
+			len(f.Qual), len(f.Seq))
+	}
+	if r.Offset == 0 {
+		if r.Offset = DetectPhredOffset(f.Qual); r.Offset == 0 {
+			r.Offset = Phred33
+		}
+	}
+	for i, q := range f.Qual {
+		if q < byte(r.Offset) || q > '~' {
+			return f, r.errorf("invalid quality symbol %q", q)
+		}
+		f.Qual[i] = q - byte(r.Offset)
+	}
+	return f, nil
+}
+
+// ReadFASTQ reads all sequences from a FASTQ stream.
+func ReadFASTQ(r io.Reader) (seq []FASTQSeq, err error) {
+	fr := NewFASTQReader(r)
+	for {
+		f, err := fr.ReadSeq()
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return seq, err
+		}
+		seq = append(seq, f)
+	}
+}
+
+// ReadFASTQFile is a high level function that reads an entire FASTQ file
+// into memory.  It calls the ReadFASTQ function.
+//
+// Gzip or BGZF compressed files are decompressed transparently.
+func ReadFASTQFile(path string) ([]FASTQSeq, error) {
+	f, err := OpenSeqFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadFASTQ(f)
+}
+
+// FASTQWriter writes FASTQ records to an underlying io.Writer.
+//
+// Output is buffered.  Call Flush after the last record is written.
+//
+// Offset is the Phred offset used to encode quality scores.
+// NewFASTQWriter initializes it to Phred33.
+type FASTQWriter struct {
+	w      *bufio.Writer
+	Offset int
+}
+
+// NewFASTQWriter constructs a FASTQWriter around an io.Writer.
+func NewFASTQWriter(w io.Writer) *FASTQWriter {
+	return &FASTQWriter{w: bufio.NewWriter(w), Offset: Phred33}
+}
+
+// WriteSeq writes a single record in four line format.
+//
+// If f.Header is empty, a header of just "@" is written.
+func (w *FASTQWriter) WriteSeq(f FASTQSeq) error {
+	if len(f.Qual) != len(f.Seq) {
+		return fmt.Errorf("FASTQ %s: %d quality scores for %d sequence symbols",
+			f.ID(), len(f.Qual), len(f.Seq))
+	}
+	h := f.Header
+	if h == "" {
+		h = "@"
+	}
+	w.w.WriteString(h)
+	w.w.WriteByte('\n')
+	w.w.Write(f.Seq)
+	w.w.WriteString("\n+\n")
+	for _, q := range f.Qual {
+		w.w.WriteByte(q + byte(w.Offset))
+	}
+	return w.w.WriteByte('\n')
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *FASTQWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// MeanQual returns the arithmetic mean of the quality scores.
+//
+// NaN is returned for an empty sequence.
+func (f FASTQSeq) MeanQual() float64 {
+	s := 0
+	for _, q := range f.Qual {
+		s += int(q)
+	}
+	return float64(s) / float64(len(f.Qual))
+}
+
+// slice returns the record restricted to the range i:j.  Header is
+// retained, Seq and Qual are resliced, not copied.
+func (f FASTQSeq) slice(i, j int) FASTQSeq {
+	return FASTQSeq{f.Header, f.Seq[i:j], f.Qual[i:j]}
+}
+
+// TrimWindow trims the 3' end of a read with a sliding window.
+//
+// The window is moved from the 5' end until the mean quality within the
+// window drops below min.  The read is cut at the start of that window.
+// If no window falls below min, the read is returned untrimmed.  Reads
+// shorter than the window are judged by the mean of the entire read.
+//
+// Seq and Qual of the result are slices of those of f.
+func (f FASTQSeq) TrimWindow(window int, min float64) FASTQSeq {
+	if window > len(f.Qual) {
+		window = len(f.Qual)
+	}
+	if window <= 0 {
+		return f
+	}
+	lim := min * float64(window)
+	s := 0
+	for _, q := range f.Qual[:window] {
+		s += int(q)
+	}
+	for i := 0; ; i++ {
+		if float64(s) < lim {
+			return f.slice(0, i)
+		}
+		if i+window == len(f.Qual) {
+			return f
+		}
+		s += int(f.Qual[i+window]) - int(f.Qual[i])
+	}
+}
+
+// TrimMott trims a read with the modified Mott algorithm.
+//
+// Each base is scored as limit minus its error probability, 10^(-Q/10).
+// The result is the subsequence with the maximum sum of scores, which may
+// be empty.  A typical limit is 0.05, corresponding to Q13.
+//
+// Seq and Qual of the result are slices of those of f.
+func (f FASTQSeq) TrimMott(limit float64) FASTQSeq {
+	var sum, max float64
+	start, bestStart, bestEnd := 0, 0, 0
+	for i, q := range f.Qual {
+		sum += limit - math.Pow(10, -float64(q)/10)
+		if sum <= 0 {
+			sum = 0
+			start = i + 1
+			continue
+		}
+		if sum > max {
+			max = sum
+			bestStart, bestEnd = start, i+1
+		}
+	}
+	return f.slice(bestStart, bestEnd)
+}
+
+// FASTA converts a FASTQSeq to a FASTASeq.
+//
+// The header is retained, with '@' replaced by '>'.  Quality scores
+// are discarded.
+func (f FASTQSeq) FASTA() FASTASeq {
+	h := f.Header
+	if h > "" {
+		h = ">" + h[1:]
+	}
+	return FASTASeq{h, f.Seq}
+}
@@ -0,0 +1,129 @@
+package bio_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleFASTQReader_ReadSeq() {
+	b := bytes.NewBufferString(`@r1 first read
+ACGTAC
+GT
++
+IIII
+II5!
+@r2
+TTGA
++r2
+@@@@
+`)
+	r := bio.NewFASTQReader(b)
+	for {
+		s, err := r.ReadSeq()
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		fmt.Println(s.ID(), s.Seq, s.Qual)
+	}
+	fmt.Println("offset:", r.Offset)
+	// Output:
+	// r1 ACGTACGT [40 40 40 40 40 40 20 0]
+	// r2 TTGA [31 31 31 31]
+	// EOF
+	// offset: 33
+}
+
+func ExampleFASTQReader_ReadSeq_phred64() {
+	b := bytes.NewBufferString(`@r1
+ACGT
++
+hhhB
+`)
+	r := bio.NewFASTQReader(b)
+	s, err := r.ReadSeq()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(s.Qual, r.Offset)
+	// Output:
+	// [40 40 40 2] 64
+}
+
+func ExampleFASTQReader_ReadSeq_malformed() {
+	b := bytes.NewBufferString(`@r1
+ACGT
++
+IIII
+@r2
+ACGT
++
+III
+`)
+	_, err := bio.ReadFASTQ(b)
+	fmt.Println(err)
+	// Output:
+	// FASTQ line 8: unexpected EOF in quality
+}
+
+func ExampleFASTQWriter() {
+	w := bio.NewFASTQWriter(os.Stdout)
+	w.WriteSeq(bio.FASTQSeq{
+		Header: "@r1 example",
+		Seq:    bio.Seq("ACGT"),
+		Qual:   []byte{40, 30, 20, 10},
+	})
+	w.Flush()
+	// Output:
+	// @r1 example
+	// ACGT
+	// +
+	// I?5+
+}
+
+func ExampleFASTQSeq_MeanQual() {
+	f := bio.FASTQSeq{Seq: bio.Seq("ACGT"), Qual: []byte{40, 30, 20, 10}}
+	fmt.Println(f.MeanQual())
+	// Output:
+	// 25
+}
+
+func ExampleFASTQSeq_TrimWindow() {
+	f := bio.FASTQSeq{
+		Seq:  bio.Seq("ACGTACGTAC"),
+		Qual: []byte{30, 30, 30, 30, 30, 30, 12, 10, 8, 2},
+	}
+	t := f.TrimWindow(3, 20)
+	fmt.Println(t.Seq, t.Qual)
+	// Output:
+	// ACGTA [30 30 30 30 30]
+}
+
+func ExampleFASTQSeq_TrimMott() {
+	f := bio.FASTQSeq{
+		Seq:  bio.Seq("ACGTACGTAC"),
+		Qual: []byte{2, 5, 30, 30, 30, 30, 30, 10, 8, 2},
+	}
+	t := f.TrimMott(.05)
+	fmt.Println(t.Seq, t.Qual)
+	// Output:
+	// GTACG [30 30 30 30 30]
+}
+
+func ExampleFASTQSeq_FASTA() {
+	f := bio.FASTQSeq{
+		Header: "@r1 example",
+		Seq:    bio.Seq("ACGT"),
+		Qual:   []byte{40, 30, 20, 10},
+	}
+	a := f.FASTA()
+	fmt.Println(a.Header)
+	fmt.Println(a.Seq)
+	// Output:
+	// >r1 example
+	// ACGT
+}
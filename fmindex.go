@@ -0,0 +1,156 @@
+package bio
+
+import (
+	"bytes"
+	"sort"
+)
+
+// FMIndex is an FM-index supporting approximate search of a string.
+//
+// It combines a BWT of the string with the string itself, which is used
+// to verify candidate matches of seed search.
+type FMIndex struct {
+	bwt  *BWT
+	text []byte
+	dna  bool // search both strands
+}
+
+// FMMatch is a match found by searching an FMIndex.
+type FMMatch struct {
+	Pos        int  // position of the match in the indexed string
+	Mismatches int  // number of mismatched symbols
+	Strand     byte // '+', or '-' for a match of the reverse complement
+}
+
+// NewFMIndex constructs an FMIndex of s.
+//
+// The string must not contain the byte 0, which is used as the sentinal of
+// the BWT.  Parameter mod is as for NewBWT.  The index retains s, which
+// should not be modified afterward.
+func NewFMIndex(s []byte, mod int) *FMIndex {
+	return &FMIndex{
+		bwt:  NewBWT(string(s), 0, mod),
+		text: s,
+	}
+}
+
+// NewFMIndexDNA8 constructs an FMIndex of a DNA sequence.
+//
+// Searches of the index find matches on both strands.  Symbols are
+// converted to upper case for indexing and search.
+func NewFMIndexDNA8(s DNA8, mod int) *FMIndex {
+	x := NewFMIndex(bytes.ToUpper(s), mod)
+	x.dna = true
+	return x
+}
+
+// Len returns the length of the indexed string.
+func (x *FMIndex) Len() int {
+	return len(x.text)
+}
+
+// patterns returns the patterns to search for with their strands.
+func (x *FMIndex) patterns(pat []byte) ([][]byte, []byte) {
+	if !x.dna {
+		return [][]byte{pat}, []byte{'+'}
+	}
+	p := DNA8(bytes.ToUpper(pat))
+	return [][]byte{p, p.ReverseComplement()}, []byte{'+', '-'}
+}
+
+// Search finds all occurrences of pat with at most k mismatches.
+//
+// The search backtracks through the BWT, trying each symbol of the
+// indexed string at each position of pat while the mismatch budget
+// allows.  Time is exponential in k, so Search is best for small k.
+// See SeedSearch for larger k.
+//
+// Results are ordered by position, then strand.
+func (x *FMIndex) Search(pat []byte, k int) []FMMatch {
+	var r []FMMatch
+	pats, strands := x.patterns(pat)
+	b := x.bwt
+	for i, p := range pats {
+		var rec func(last, top, bot, mm int)
+		rec = func(last, top, bot, mm int) {
+			if last < 0 {
+				for j := top; j <= bot; j++ {
+					r = append(r, FMMatch{b.locate(j), mm, strands[i]})
+				}
+				return
+			}
+			for _, sym := range b.sx[1:] {
+				m := mm
+				if sym != p[last] {
+					if m++; m > k {
+						continue
+					}
+				}
+				if t, u := b.extend(sym, top, bot); t <= u {
+					rec(last-1, t, u, m)
+				}
+			}
+		}
+		rec(len(p)-1, 0, len(b.bwt)-1, 0)
+	}
+	sort.Sort(fmMatchList(r))
+	return r
+}
+
+// SeedSearch finds all occurrences of pat with at most k mismatches.
+//
+// By the pigeonhole principle, any such occurrence matches at least one of
+// k+1 non-overlapping pieces of pat exactly.  SeedSearch locates the exact
+// matches of the pieces with the BWT and verifies the candidates against
+// the indexed string.  It is efficient for larger k as long as the pieces
+// are not too short to be selective.
+//
+// Results are the same as those of Search.
+func (x *FMIndex) SeedSearch(pat []byte, k int) []FMMatch {
+	var r []FMMatch
+	pats, strands := x.patterns(pat)
+	n := len(pat)
+	if k >= n {
+		// every position matches.  seeds would be empty.
+		return x.Search(pat, k)
+	}
+	for i, p := range pats {
+		seen := map[int]bool{}
+		for s := 0; s <= k; s++ {
+			off := s * n / (k + 1)
+			end := (s + 1) * n / (k + 1)
+			for _, q := range x.bwt.AllIndex(string(p[off:end])) {
+				st := q - off
+				if st < 0 || st+n > len(x.text) || seen[st] {
+					continue
+				}
+				seen[st] = true
+				mm := 0
+				for j, c := range p {
+					if x.text[st+j] != c {
+						if mm++; mm > k {
+							break
+						}
+					}
+				}
+				if mm <= k {
+					r = append(r, FMMatch{st, mm, strands[i]})
+				}
+			}
+		}
+	}
+	sort.Sort(fmMatchList(r))
+	return r
+}
+
+// fmMatchList is a container type satisfying sort.Interface.
+type fmMatchList []FMMatch
+
+func (l fmMatchList) Len() int      { return len(l) }
+func (l fmMatchList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l fmMatchList) Less(i, j int) bool {
+	if l[i].Pos != l[j].Pos {
+		return l[i].Pos < l[j].Pos
+	}
+	return l[i].Strand < l[j].Strand
+}
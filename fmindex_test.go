@@ -0,0 +1,60 @@
+package bio_test
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleFMIndex_Search() {
+	x := bio.NewFMIndexDNA8(bio.DNA8("ACGTTGCAGGACTTACGAAT"), 4)
+	for _, m := range x.Search([]byte("ttgc"), 1) {
+		fmt.Println(m.Pos, m.Mismatches, string(m.Strand))
+	}
+	// Output:
+	// 3 0 +
+	// 5 1 -
+	// 12 1 +
+}
+
+func ExampleFMIndex_SeedSearch() {
+	x := bio.NewFMIndex([]byte("the quick brown fox jumps over the lazy dog"), 8)
+	for _, m := range x.SeedSearch([]byte("the hazy fog"), 2) {
+		fmt.Println(m.Pos, m.Mismatches, string(m.Strand))
+	}
+	// Output:
+	// 31 2 +
+}
+
+func TestFMIndex(t *testing.T) {
+	s := make(bio.DNA8, 500)
+	for i := range s {
+		s[i] = "ACGT"[rand.Intn(4)]
+	}
+	x := bio.NewFMIndexDNA8(s, 16)
+	for _, n := range []int{4, 7, 12} {
+		for k := 0; k <= 3; k++ {
+			p := make(bio.DNA8, n)
+			copy(p, s[rand.Intn(len(s)-n):])
+			prc := p.ReverseComplement()
+			var want []bio.FMMatch
+			for i := 0; i+n <= len(s); i++ {
+				if d := s[i : i+n].Hamming(p); d <= k {
+					want = append(want, bio.FMMatch{i, d, '+'})
+				}
+				if d := s[i : i+n].Hamming(prc); d <= k {
+					want = append(want, bio.FMMatch{i, d, '-'})
+				}
+			}
+			if got := x.Search(p, k); !reflect.DeepEqual(got, want) {
+				t.Fatalf("Search n %d k %d: got %v, want %v", n, k, got, want)
+			}
+			if got := x.SeedSearch(p, k); !reflect.DeepEqual(got, want) {
+				t.Fatalf("SeedSearch n %d k %d: got %v, want %v", n, k, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,503 @@
+package bio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GenBankRecord is a single record of a GenBank flat file.
+//
+// Only a selection of fields are parsed.  Others are skipped.
+type GenBankRecord struct {
+	Locus      GenBankLocus
+	Definition string
+	Accession  string // primary accession number
+	Version    string // accession.version
+	Features   []GenBankFeature
+	Seq        DNA8 // sequence of ORIGIN section, lower case as in the file
+}
+
+// GenBankLocus holds fields of the LOCUS line.
+type GenBankLocus struct {
+	Name     string
+	Length   int
+	MolType  string // for example DNA, mRNA
+	Topology string // linear, circular, or empty if not given
+	Division string // for example BCT, PLN
+	Date     string
+}
+
+// GenBankFeature is an entry of the FEATURES table.
+type GenBankFeature struct {
+	Key        string // feature key, for example gene, CDS
+	Location   string // location as written
+	Spans      []FeatureSpan
+	Qualifiers []GenBankQualifier
+}
+
+// GenBankQualifier is a qualifier of a feature.
+//
+// Value has enclosing quotes removed and continuation lines joined.
+// Qualifiers with no value, such as /pseudo, have an empty Value.
+type GenBankQualifier struct {
+	Name  string // name without the leading /
+	Value string
+}
+
+// FeatureSpan is a single contiguous region of a feature location.
+//
+// Start and End are 0-based and the range is half-open, in the coordinates
+// of the forward strand.  Complement indicates the span is on the reverse
+// strand.  PartialStart and PartialEnd indicate the < and > markers on the
+// low and high coordinates respectively.
+type FeatureSpan struct {
+	Start, End   int
+	Complement   bool
+	PartialStart bool
+	PartialEnd   bool
+}
+
+// ParseLocation parses a GenBank feature location.
+//
+// Supported are simple ranges such as 10..20 with optional < and > partial
+// markers, single bases, sites between bases such as 10^11, and the
+// operators complement, join, and order.  The result lists spans in the
+// order of the feature on its strand, so for example the spans of
+// complement(join(1..10,20..30)) are 20..30 followed by 1..10, both
+// complemented.  Remote references to other entries are not supported.
+func ParseLocation(loc string) ([]FeatureSpan, error) {
+	p := &locParser{s: strings.Replace(loc, " ", "", -1)}
+	spans, err := p.location()
+	if err == nil && p.s != "" {
+		err = errors.New("unexpected text " + p.s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("location %s: %v", loc, err)
+	}
+	return spans, nil
+}
+
+type locParser struct {
+	s string // remaining text
+}
+
+func (p *locParser) location() ([]FeatureSpan, error) {
+	switch {
+	case strings.HasPrefix(p.s, "complement("):
+		p.s = p.s[len("complement("):]
+		l, err := p.location()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(')'); err != nil {
+			return nil, err
+		}
+		r := make([]FeatureSpan, len(l))
+		for i, sp := range l {
+			sp.Complement = !sp.Complement
+			r[len(l)-1-i] = sp
+		}
+		return r, nil
+	case strings.HasPrefix(p.s, "join("):
+		p.s = p.s[len("join("):]
+		return p.list()
+	case strings.HasPrefix(p.s, "order("):
+		p.s = p.s[len("order("):]
+		return p.list()
+	}
+	sp, err := p.span()
+	if err != nil {
+		return nil, err
+	}
+	return []FeatureSpan{sp}, nil
+}
+
+// list parses a comma separated list of locations and a closing ')'.
+func (p *locParser) list() (r []FeatureSpan, err error) {
+	for {
+		l, err := p.location()
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, l...)
+		if p.s == "" || p.s[0] != ',' {
+			break
+		}
+		p.s = p.s[1:]
+	}
+	return r, p.expect(')')
+}
+
+func (p *locParser) expect(c byte) error {
+	if p.s == "" || p.s[0] != c {
+		return fmt.Errorf("expected %c", c)
+	}
+	p.s = p.s[1:]
+	return nil
+}
+
+// span parses a range, single base, or site.
+func (p *locParser) span() (sp FeatureSpan, err error) {
+	if strings.IndexByte(p.s, ':') >= 0 {
+		if c := strings.IndexAny(p.s, ",()"); c < 0 || c > strings.IndexByte(p.s, ':') {
+			return sp, errors.New("remote location not supported")
+		}
+	}
+	if p.s != "" && p.s[0] == '<' {
+		sp.PartialStart = true
+		p.s = p.s[1:]
+	}
+	start, err := p.int()
+	if err != nil {
+		return sp, err
+	}
+	switch {
+	case strings.HasPrefix(p.s, ".."):
+		p.s = p.s[2:]
+		if p.s != "" && p.s[0] == '>' {
+			sp.PartialEnd = true
+			p.s = p.s[1:]
+		}
+		end, err := p.int()
+		if err != nil {
+			return sp, err
+		}
+		sp.Start, sp.End = start-1, end
+	case strings.HasPrefix(p.s, "^"):
+		// site between two bases, represented as an empty span
+		p.s = p.s[1:]
+		if _, err := p.int(); err != nil {
+			return sp, err
+		}
+		sp.Start, sp.End = start, start
+	default:
+		if p.s != "" && p.s[0] == '>' {
+			sp.PartialEnd = true
+			p.s = p.s[1:]
+		}
+		sp.Start, sp.End = start-1, start
+	}
+	if sp.End < sp.Start {
+		return sp, errors.New("range end before start")
+	}
+	return sp, nil
+}
+
+func (p *locParser) int() (int, error) {
+	i := 0
+	for i < len(p.s) && p.s[i] >= '0' && p.s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, errors.New("expected number")
+	}
+	n, err := strconv.Atoi(p.s[:i])
+	p.s = p.s[i:]
+	return n, err
+}
+
+// Qualifier returns the value of the first qualifier with the given name.
+//
+// Result ok is false if the feature has no such qualifier.
+func (f GenBankFeature) Qualifier(name string) (value string, ok bool) {
+	for _, q := range f.Qualifiers {
+		if q.Name == name {
+			return q.Value, true
+		}
+	}
+	return "", false
+}
+
+// Extract returns the sequence of the feature.
+//
+// Spans are concatenated in order with complemented spans reverse
+// complemented, so for example the result for a CDS is the spliced coding
+// sequence.  Argument s is the sequence of the record, GenBankRecord.Seq.
+// The result is nil if any span lies outside s, as for a record with no
+// ORIGIN section.
+func (f GenBankFeature) Extract(s DNA8) DNA8 {
+	var r DNA8
+	for _, sp := range f.Spans {
+		if sp.Start < 0 || sp.End > len(s) {
+			return nil
+		}
+		if sp.Complement {
+			r = append(r, s[sp.Start:sp.End].ReverseComplement()...)
+		} else {
+			r = append(r, s[sp.Start:sp.End]...)
+		}
+	}
+	return r
+}
+
+// Translate translates the extracted sequence of a CDS feature.
+//
+// Translation begins at the position given by the /codon_start qualifier,
+// if present, and proceeds as by DNA8.TranslateCode, using the genetic code
+// given by the /transl_table qualifier or by default the standard code.
+// If the feature is complete at its 5' end and begins with a start codon
+// of the genetic code, the first amino acid is translated as M.
+func (f GenBankFeature) Translate(s DNA8) (p AA20, stop bool) {
+	c := f.Extract(s)
+	if cs, ok := f.Qualifier("codon_start"); ok {
+		if n, err := strconv.Atoi(cs); err == nil && n > 1 && n <= len(c) {
+			c = c[n-1:]
+		}
+	}
+	code := StandardCode
+	if t, ok := f.Qualifier("transl_table"); ok {
+		if n, err := strconv.Atoi(t); err == nil && GeneticCodeByID(n) != nil {
+			code = GeneticCodeByID(n)
+		}
+	}
+	p, stop = c.TranslateCode(code)
+	if len(p) > 0 && !f.partial5() && code.IsStartCodon(c[0], c[1], c[2]) {
+		p[0] = 'M'
+	}
+	return
+}
+
+// partial5 returns true if the feature is partial at its 5' end.
+func (f GenBankFeature) partial5() bool {
+	if len(f.Spans) == 0 {
+		return false
+	}
+	sp := f.Spans[0]
+	if sp.Complement {
+		return sp.PartialEnd
+	}
+	return sp.PartialStart
+}
+
+// CheckTranslation compares the translation of a CDS feature, as computed
+// by the Translate method, with the value of its /translation qualifier.
+//
+// It returns false if the feature has no /translation qualifier.
+func (f GenBankFeature) CheckTranslation(s DNA8) bool {
+	t, ok := f.Qualifier("translation")
+	if !ok {
+		return false
+	}
+	p, _ := f.Translate(s)
+	return string(p) == t
+}
+
+// GenBankReader reads records from a GenBank flat file.
+type GenBankReader struct {
+	r    *bufio.Reader
+	line int // number of lines read
+}
+
+// NewGenBankReader constructs a GenBankReader around an io.Reader.
+func NewGenBankReader(r io.Reader) GenBankReader {
+	return GenBankReader{r: bufio.NewReader(r)}
+}
+
+func (r *GenBankReader) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("GenBank line %d: "+format,
+		append([]interface{}{r.line}, a...)...)
+}
+
+// ReadRecord returns a single record on each call.
+//
+// Text before the LOCUS line of a record is ignored.
+// A successful read is indicated by err = nil for all records, including
+// the last.  Subsequent calls return err = io.EOF.
+func (r *GenBankReader) ReadRecord() (*GenBankRecord, error) {
+	var g *GenBankRecord
+	var keyword string       // current top level keyword
+	var feat *GenBankFeature // current feature
+	var qual *GenBankQualifier
+	inLoc := false // location of feat may continue
+	for {
+		b, err := readLine(r.r)
+		if err == io.EOF {
+			if g != nil {
+				err = r.errorf("unexpected EOF, no //")
+			}
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		r.line++
+		line := strings.TrimRight(string(b), " \t\r")
+		if g == nil {
+			if strings.HasPrefix(line, "LOCUS ") {
+				g = &GenBankRecord{}
+				if err := r.parseLocus(g, line[5:]); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if line == "//" {
+			if feat != nil {
+				if err := r.finishFeature(feat); err != nil {
+					return nil, err
+				}
+			}
+			return g, nil
+		}
+		if line != "" && line[0] != ' ' {
+			// new top level keyword
+			if feat != nil {
+				if err := r.finishFeature(feat); err != nil {
+					return nil, err
+				}
+				feat = nil
+			}
+			keyword = line
+			if sp := strings.IndexByte(line, ' '); sp >= 0 {
+				keyword = line[:sp]
+			}
+			line = strings.TrimSpace(line[len(keyword):])
+			switch keyword {
+			case "DEFINITION":
+				g.Definition = line
+			case "ACCESSION":
+				if f := strings.Fields(line); len(f) > 0 {
+					g.Accession = f[0]
+				}
+			case "VERSION":
+				if f := strings.Fields(line); len(f) > 0 {
+					g.Version = f[0]
+				}
+			}
+			continue
+		}
+		switch keyword {
+		case "DEFINITION":
+			g.Definition += " " + strings.TrimSpace(line)
+		case "ORIGIN":
+			for _, c := range []byte(line) {
+				if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' {
+					g.Seq = append(g.Seq, c)
+				}
+			}
+		case "FEATURES":
+			if len(line) < 21 {
+				return nil, r.errorf("short feature table line")
+			}
+			if k := strings.TrimSpace(line[:21]); k != "" {
+				// new feature
+				if feat != nil {
+					if err := r.finishFeature(feat); err != nil {
+						return nil, err
+					}
+				}
+				g.Features = append(g.Features, GenBankFeature{
+					Key:      k,
+					Location: line[21:],
+				})
+				feat = &g.Features[len(g.Features)-1]
+				qual = nil
+				inLoc = true
+				continue
+			}
+			if feat == nil {
+				return nil, r.errorf("qualifier without feature")
+			}
+			t := line[21:]
+			switch {
+			case qual != nil && strings.Count(qual.Value, `"`)%2 == 1:
+				// continuation of quoted value
+				qual.Value += " " + t
+			case t != "" && t[0] == '/':
+				inLoc = false
+				q := GenBankQualifier{Name: t[1:]}
+				if eq := strings.IndexByte(t, '='); eq >= 0 {
+					q.Name = t[1:eq]
+					q.Value = t[eq+1:]
+				}
+				feat.Qualifiers = append(feat.Qualifiers, q)
+				qual = &feat.Qualifiers[len(feat.Qualifiers)-1]
+			case inLoc:
+				feat.Location += t
+			case qual != nil:
+				qual.Value += " " + t
+			}
+		}
+	}
+}
+
+func (r *GenBankReader) parseLocus(g *GenBankRecord, s string) error {
+	f := strings.Fields(s)
+	if len(f) < 2 {
+		return r.errorf("invalid LOCUS line")
+	}
+	l := &g.Locus
+	l.Name = f[0]
+	var err error
+	if l.Length, err = strconv.Atoi(f[1]); err != nil {
+		return r.errorf("invalid LOCUS length %s", f[1])
+	}
+	f = f[2:]
+	if len(f) > 0 && (f[0] == "bp" || f[0] == "aa") {
+		f = f[1:]
+	}
+	if len(f) > 0 {
+		l.MolType = f[0]
+		f = f[1:]
+	}
+	if len(f) > 0 && (f[0] == "linear" || f[0] == "circular") {
+		l.Topology = f[0]
+		f = f[1:]
+	}
+	if len(f) > 1 {
+		l.Division = f[0]
+		f = f[1:]
+	}
+	if len(f) > 0 {
+		l.Date = f[0]
+	}
+	return nil
+}
+
+// finishFeature parses the location and cleans up qualifier values.
+func (r *GenBankReader) finishFeature(f *GenBankFeature) (err error) {
+	if f.Spans, err = ParseLocation(f.Location); err != nil {
+		return r.errorf("feature %s: %v", f.Key, err)
+	}
+	for i := range f.Qualifiers {
+		q := &f.Qualifiers[i]
+		if v := q.Value; len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+			q.Value = strings.Replace(v[1:len(v)-1], `""`, `"`, -1)
+		}
+		if q.Name == "translation" {
+			q.Value = strings.Replace(q.Value, " ", "", -1)
+		}
+	}
+	return nil
+}
+
+// ReadGenBank reads all records from a GenBank flat file.
+func ReadGenBank(r io.Reader) (g []*GenBankRecord, err error) {
+	gr := NewGenBankReader(r)
+	for {
+		rec, err := gr.ReadRecord()
+		switch {
+		case err == io.EOF:
+			return g, nil
+		case err != nil:
+			return nil, err
+		}
+		g = append(g, rec)
+	}
+}
+
+// ReadGenBankFile reads all records from a GenBank file.
+//
+// Gzip or BGZF compressed files are decompressed transparently.
+func ReadGenBankFile(path string) ([]*GenBankRecord, error) {
+	f, err := OpenSeqFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadGenBank(f)
+}
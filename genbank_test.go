@@ -0,0 +1,125 @@
+package bio_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+const genBankTestData = `LOCUS       TEST01                    60 bp    DNA     linear   SYN 16-OCT-2016
+DEFINITION  Synthetic test sequence with a spliced gene and a gene on the
+            reverse strand.
+ACCESSION   XX000001 XX000002
+VERSION     XX000001.1
+KEYWORDS    .
+FEATURES             Location/Qualifiers
+     source          1..60
+                     /organism="synthetic construct"
+                     /mol_type="other DNA"
+     CDS             join(3..11,
+                     20..28)
+                     /gene="abc"
+                     /note="a note that spans
+                     two lines"
+                     /translation="MKRGW"
+     CDS             complement(41..>49)
+                     /pseudo
+                     /translation="MF"
+ORIGIN      
+        1 ggatgaaacg tttttggggg gctggtaacc cccccccccc tcaaaacata aaaaaaaaaa
+//
+`
+
+func ExampleGenBankReader() {
+	r := bio.NewGenBankReader(strings.NewReader(genBankTestData))
+	g, err := r.ReadRecord()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%+v\n", g.Locus)
+	fmt.Println(g.Definition)
+	fmt.Println(g.Accession, g.Version)
+	for _, f := range g.Features {
+		fmt.Println(f.Key, f.Location, f.Spans)
+		for _, q := range f.Qualifiers {
+			fmt.Printf("  %s=%q\n", q.Name, q.Value)
+		}
+	}
+	fmt.Println(len(g.Seq))
+	_, err = r.ReadRecord()
+	fmt.Println(err)
+	// Output:
+	// {Name:TEST01 Length:60 MolType:DNA Topology:linear Division:SYN Date:16-OCT-2016}
+	// Synthetic test sequence with a spliced gene and a gene on the reverse strand.
+	// XX000001 XX000001.1
+	// source 1..60 [{0 60 false false false}]
+	//   organism="synthetic construct"
+	//   mol_type="other DNA"
+	// CDS join(3..11,20..28) [{2 11 false false false} {19 28 false false false}]
+	//   gene="abc"
+	//   note="a note that spans two lines"
+	//   translation="MKRGW"
+	// CDS complement(41..>49) [{40 49 true false true}]
+	//   pseudo=""
+	//   translation="MF"
+	// 60
+	// EOF
+}
+
+func ExampleGenBankFeature_Extract() {
+	g, err := bio.ReadGenBank(strings.NewReader(genBankTestData))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	s := g[0].Seq
+	for _, f := range g[0].Features[1:] {
+		fmt.Println(f.Extract(s))
+		fmt.Println(f.Translate(s))
+		fmt.Println(f.CheckTranslation(s))
+	}
+	// Output:
+	// atgaaacgtggctggtaa
+	// MKRGW true
+	// true
+	// atgttttga
+	// MF true
+	// true
+}
+
+func ExampleParseLocation() {
+	fmt.Println(bio.ParseLocation("complement(join(<1..10,20..30))"))
+	fmt.Println(bio.ParseLocation("order(5,8^9)"))
+	fmt.Println(bio.ParseLocation("join(1..10"))
+	// Output:
+	// [{19 30 true false false} {0 10 true true false}] <nil>
+	// [{4 5 false false false} {8 8 false false false}] <nil>
+	// [] location join(1..10: expected )
+}
+
+// TestGenBankExtractBounds checks spans outside the record sequence.
+func TestGenBankExtractBounds(t *testing.T) {
+	g, err := bio.ReadGenBank(strings.NewReader(genBankTestData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cds, rc := g[0].Features[1], g[0].Features[2]
+	short := g[0].Seq[:30]
+	if cds.Extract(short) == nil || !cds.CheckTranslation(short) {
+		t.Fatal(cds.Location, "not extracted from", short)
+	}
+	for _, c := range []struct {
+		f bio.GenBankFeature
+		s bio.DNA8
+	}{{cds, nil}, {rc, nil}, {rc, short}} {
+		if r := c.f.Extract(c.s); r != nil {
+			t.Fatalf("%s from %q: %s", c.f.Location, c.s, r)
+		}
+		if p, _ := c.f.Translate(c.s); len(p) > 0 {
+			t.Fatalf("%s from %q: translated %s", c.f.Location, c.s, p)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package bio
+
+import (
+	"regexp"
+	"sort"
+)
+
+// GeneticCode is a translation table from codons to amino acids with a set
+// of start codons.
+//
+// Tables are those of NCBI, identified by their NCBI ID.  Use
+// GeneticCodeByID to obtain a table.  StandardCode, table 1, is used by
+// functions and methods that do not take a GeneticCode argument.
+//
+// For tables 27, 28, and 31, where some codons are either stop codons or
+// amino acids depending on context, the codons translate as amino acids.
+type GeneticCode struct {
+	ID    int
+	Name  string
+	table [64]byte // amino acids by CodonIndex
+	start [64]bool // start codons by CodonIndex
+}
+
+// NCBI tables, from the NCBI gc.prt file.  AAs and Starts strings are
+// in codon order TCAG.
+var ncbiCodes = []struct {
+	id          int
+	name        string
+	aas, starts string
+}{
+	{1, "Standard",
+		"FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"---M---------------M---------------M----------------------------"},
+	{2, "Vertebrate Mitochondrial",
+		"FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSS**VVVVAAAADDEEGGGG",
+		"--------------------------------MMMM---------------M------------"},
+	{3, "Yeast Mitochondrial",
+		"FFLLSSSSYY**CCWWTTTTPPPPHHQQRRRRIIMMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"----------------------------------MM---------------M------------"},
+	{4, "Mold Mitochondrial; Protozoan Mitochondrial; Coelenterate " +
+		"Mitochondrial; Mycoplasma; Spiroplasma",
+		"FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"--MM---------------M------------MMMM---------------M------------"},
+	{5, "Invertebrate Mitochondrial",
+		"FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSSSVVVVAAAADDEEGGGG",
+		"---M----------------------------MMMM---------------M------------"},
+	{6, "Ciliate Nuclear; Dasycladacean Nuclear; Hexamita Nuclear",
+		"FFLLSSSSYYQQCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-----------------------------------M----------------------------"},
+	{9, "Echinoderm Mitochondrial; Flatworm Mitochondrial",
+		"FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+		"-----------------------------------M---------------M------------"},
+	{10, "Euplotid Nuclear",
+		"FFLLSSSSYY**CCCWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-----------------------------------M----------------------------"},
+	{11, "Bacterial, Archaeal and Plant Plastid",
+		"FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"---M---------------M------------MMMM---------------M------------"},
+	{12, "Alternative Yeast Nuclear",
+		"FFLLSSSSYY**CC*WLLLSPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-------------------M---------------M----------------------------"},
+	{13, "Ascidian Mitochondrial",
+		"FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSGGVVVVAAAADDEEGGGG",
+		"---M------------------------------MM---------------M------------"},
+	{14, "Alternative Flatworm Mitochondrial",
+		"FFLLSSSSYYY*CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+		"-----------------------------------M----------------------------"},
+	{15, "Blepharisma Macronuclear",
+		"FFLLSSSSYY*QCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-----------------------------------M----------------------------"},
+	{16, "Chlorophycean Mitochondrial",
+		"FFLLSSSSYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-----------------------------------M----------------------------"},
+	{21, "Trematode Mitochondrial",
+		"FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+		"-----------------------------------M---------------M------------"},
+	{22, "Scenedesmus obliquus Mitochondrial",
+		"FFLLSS*SYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-----------------------------------M----------------------------"},
+	{23, "Thraustochytrium Mitochondrial",
+		"FF*LSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"--------------------------------M--M---------------M------------"},
+	{24, "Rhabdopleuridae Mitochondrial",
+		"FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSSKVVVVAAAADDEEGGGG",
+		"---M---------------M---------------M---------------M------------"},
+	{25, "Candidate Division SR1 and Gracilibacteria",
+		"FFLLSSSSYY**CCGWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"---M-------------------------------M---------------M------------"},
+	{26, "Pachysolen tannophilus Nuclear",
+		"FFLLSSSSYY**CC*WLLLAPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-------------------M---------------M----------------------------"},
+	{27, "Karyorelict Nuclear",
+		"FFLLSSSSYYQQCCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-----------------------------------M----------------------------"},
+	{28, "Condylostoma Nuclear",
+		"FFLLSSSSYYQQCCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-----------------------------------M----------------------------"},
+	{29, "Mesodinium Nuclear",
+		"FFLLSSSSYYYYCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-----------------------------------M----------------------------"},
+	{30, "Peritrich Nuclear",
+		"FFLLSSSSYYEECC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-----------------------------------M----------------------------"},
+	{31, "Blastocrithidia Nuclear",
+		"FFLLSSSSYYEECCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"-----------------------------------M----------------------------"},
+	{32, "Balanophoraceae Plastid",
+		"FFLLSSSSYY*WCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+		"---M---------------M------------MMMM---------------M------------"},
+	{33, "Cephalodiscidae Mitochondrial",
+		"FFLLSSSSYYY*CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSSKVVVVAAAADDEEGGGG",
+		"---M-------------------------------M---------------M------------"},
+}
+
+var geneticCodes = map[int]*GeneticCode{}
+
+// StandardCode is NCBI translation table 1, the standard genetic code.
+var StandardCode *GeneticCode
+
+func init() {
+	const tcag = "TCAG"
+	for _, n := range ncbiCodes {
+		c := &GeneticCode{ID: n.id, Name: n.name}
+		for i := 0; i < 64; i++ {
+			x := CodonIndex(tcag[i>>4], tcag[i>>2&3], tcag[i&3])
+			c.table[x] = n.aas[i]
+			c.start[x] = n.starts[i] == 'M'
+		}
+		geneticCodes[n.id] = c
+	}
+	StandardCode = geneticCodes[1]
+}
+
+// GeneticCodeByID returns the NCBI translation table with the given ID.
+//
+// Nil is returned if there is no table with the ID.
+func GeneticCodeByID(id int) *GeneticCode {
+	return geneticCodes[id]
+}
+
+// GeneticCodeIDs returns the IDs of all available translation tables in
+// increasing order.
+func GeneticCodeIDs() []int {
+	ids := make([]int, 0, len(geneticCodes))
+	for id := range geneticCodes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// TranslateCodon translates a sequence of three DNA8 or RNA8 bases into
+// an amino acid symbol or the stop symbol AAStop.
+func (c *GeneticCode) TranslateCodon(b0, b1, b2 byte) byte {
+	return c.table[CodonIndex(b0, b1, b2)]
+}
+
+// IsStartCodon tests for a start codon of the table.
+//
+// The arguments should be a sequence of DNA8 or RNA8 symbols.
+func (c *GeneticCode) IsStartCodon(b0, b1, b2 byte) bool {
+	return c.start[CodonIndex(b0, b1, b2)]
+}
+
+// StartCodons returns the start codons of the table as upper case DNA.
+func (c *GeneticCode) StartCodons() (s []DNA8) {
+	k := DNA8("AAA")
+	for {
+		if c.start[CodonIndex(k[0], k[1], k[2])] {
+			s = append(s, append(DNA8{}, k...))
+		}
+		if !k.Inc() {
+			return
+		}
+	}
+}
+
+// translateCode translates DNA or RNA with a genetic code.  If c is nil
+// the standard code is used.
+func translateCode(seq []byte, c *GeneticCode) (a AA20, stop bool) {
+	if c == nil {
+		return translate8(seq)
+	}
+	for p := 2; p < len(seq); p += 3 {
+		aa := c.TranslateCodon(seq[p-2], seq[p-1], seq[p])
+		if aa == AAStop {
+			return a, true
+		}
+		a = append(a, aa)
+	}
+	return a, false
+}
+
+// TranslateCode translates the receiver DNA sequence into an amino acid
+// sequence using genetic code c.
+//
+// It is otherwise the same as the Translate method.  In particular a start
+// codon is neither required nor treated specially.  If c is nil the
+// standard code is used.
+func (s DNA8) TranslateCode(c *GeneticCode) (p AA20, stop bool) {
+	return translateCode(s, c)
+}
+
+// TranslateCode translates the receiver RNA sequence into an amino acid
+// sequence using genetic code c.
+//
+// It is otherwise the same as the Translate method.  If c is nil the
+// standard code is used.
+func (s RNA8) TranslateCode(c *GeneticCode) (p AA20, stop bool) {
+	return translateCode(s, c)
+}
+
+// startRx returns a regular expression matching the start codons of c.
+func (c *GeneticCode) startRx() *regexp.Regexp {
+	rx := "(?i)"
+	for i, s := range c.StartCodons() {
+		if i > 0 {
+			rx += "|"
+		}
+		rx += string(s)
+	}
+	return regexp.MustCompile(rx)
+}
+
+// TranslateORFCode locates and translates all open reading frames in
+// a sequence using genetic code c.
+//
+// Open reading frames begin at any start codon of c.  An alternative start
+// codon is translated as M.  Returned is a collection of all unique amino
+// acid sequences.  If c is nil the standard code is used.
+//
+// Note that the standard code has start codons TTG and CTG as well as ATG.
+// Unlike TranslateORF, TranslateORFCode(StandardCode) uses all three.
+func (s DNA8) TranslateORFCode(c *GeneticCode) []AA20 {
+	if c == nil {
+		c = StandardCode
+	}
+	rx := c.startRx()
+	m := map[string]AA20{}
+	orf := func(s DNA8) {
+		for {
+			start := rx.FindIndex(s)
+			if start == nil {
+				return
+			}
+			s = s[start[0]:]
+			ps, stop := translateCode(s, c)
+			if stop {
+				ps[0] = 'M'
+				m[string(ps)] = ps
+			}
+			s = s[1:]
+		}
+	}
+	orf(s)
+	orf(s.ReverseComplement())
+	r := make([]AA20, 0, len(m))
+	for _, p := range m {
+		r = append(r, p)
+	}
+	return r
+}
@@ -0,0 +1,72 @@
+package bio_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+// TestStandardCode checks table 1 against CodonTable.
+func TestStandardCode(t *testing.T) {
+	k := bio.DNA8("AAA")
+	for {
+		want := bio.TranslateCodon(k[0], k[1], k[2])
+		if got := bio.StandardCode.TranslateCodon(k[0], k[1], k[2]); got != want {
+			t.Fatalf("%s: %c, want %c", k, got, want)
+		}
+		if !k.Inc() {
+			break
+		}
+	}
+}
+
+func ExampleGeneticCodeIDs() {
+	fmt.Println(bio.GeneticCodeIDs())
+	c := bio.GeneticCodeByID(32)
+	fmt.Printf("%s %c\n", c.Name, c.TranslateCodon('T', 'A', 'G'))
+	// Output:
+	// [1 2 3 4 5 6 9 10 11 12 13 14 15 16 21 22 23 24 25 26 27 28 29 30 31 32 33]
+	// Balanophoraceae Plastid W
+}
+
+func ExampleGeneticCode_TranslateCodon() {
+	mt := bio.GeneticCodeByID(2)
+	fmt.Println(mt.Name)
+	fmt.Printf("%c %c\n", bio.StandardCode.TranslateCodon('T', 'G', 'A'),
+		mt.TranslateCodon('T', 'G', 'A'))
+	fmt.Printf("%c %c\n", bio.StandardCode.TranslateCodon('A', 'G', 'A'),
+		mt.TranslateCodon('A', 'G', 'A'))
+	// Output:
+	// Vertebrate Mitochondrial
+	// * W
+	// R *
+}
+
+func ExampleGeneticCode_StartCodons() {
+	fmt.Println(bio.StandardCode.StartCodons())
+	fmt.Println(bio.GeneticCodeByID(11).StartCodons())
+	fmt.Println(bio.GeneticCodeByID(11).IsStartCodon('g', 'u', 'g'))
+	// Output:
+	// [ATG CTG TTG]
+	// [ATA ATC ATT ATG CTG TTG GTG]
+	// true
+}
+
+func ExampleDNA8_TranslateCode() {
+	s := bio.DNA8("ATGTGGTGAAAATAG")
+	fmt.Println(s.Translate())
+	fmt.Println(s.TranslateCode(bio.GeneticCodeByID(2)))
+	// Output:
+	// MW true
+	// MWWK true
+}
+
+func ExampleDNA8_TranslateORFCode() {
+	s := bio.DNA8("CCGTGAAACCCTAAGG")
+	fmt.Println(s.TranslateORF())
+	fmt.Println(s.TranslateORFCode(bio.GeneticCodeByID(11)))
+	// Output:
+	// []
+	// [MKP]
+}
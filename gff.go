@@ -0,0 +1,306 @@
+package bio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GFFFeature is a single feature line of a GFF3 file.
+//
+// Start and End are converted from the 1-based closed coordinates of GFF3
+// to 0-based half-open coordinates, as with Go slice expressions.
+type GFFFeature struct {
+	SeqID      string
+	Source     string
+	Type       string
+	Start, End int
+	Score      float64 // NaN if not given
+	Strand     byte    // '+', '-', '.', or '?'
+	Phase      int     // 0, 1, 2, or -1 if not given
+	Attributes []GFFAttribute
+}
+
+// GFFAttribute is a tag-value pair of the attributes column of GFF3.
+//
+// Values are split on commas and percent-decoded.
+type GFFAttribute struct {
+	Tag    string
+	Values []string
+}
+
+// Attr returns the first value of the attribute with the given tag.
+//
+// Result ok is false if the feature has no such attribute.
+func (f GFFFeature) Attr(tag string) (value string, ok bool) {
+	for _, a := range f.Attributes {
+		if a.Tag == tag {
+			if len(a.Values) > 0 {
+				value = a.Values[0]
+			}
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// ID returns the value of the ID attribute, or an empty string.
+func (f GFFFeature) ID() string {
+	id, _ := f.Attr("ID")
+	return id
+}
+
+// Interval returns the coordinates of the feature.
+func (f GFFFeature) Interval() Interval {
+	return Interval{f.Start, f.End}
+}
+
+// Extract returns the sequence of the feature from a reference sequence.
+//
+// The ID of s must match f.SeqID.  The result is reverse complemented
+// for features on the '-' strand.
+func (f GFFFeature) Extract(s FASTASeq) (DNA8, error) {
+	return extract([]Interval{f.Interval()}, f.Strand,
+		fastaRegion(s, f.SeqID))
+}
+
+// ExtractIndexed returns the sequence of the feature from an indexed
+// reference.
+//
+// The result is reverse complemented for features on the '-' strand.
+func (f GFFFeature) ExtractIndexed(x *IndexedFASTA) (DNA8, error) {
+	return extract([]Interval{f.Interval()}, f.Strand,
+		indexedRegion(x, f.SeqID))
+}
+
+// GFFReader reads features from a GFF3 stream.
+//
+// Directive lines, those starting with ##, are collected in Directives.
+// Reading stops at a ##FASTA directive.  Sequences following the directive
+// can then be read with the FASTA method.
+type GFFReader struct {
+	r          *bufio.Reader
+	line       int // number of lines read
+	fasta      bool
+	Directives []string
+}
+
+// NewGFFReader constructs a GFFReader around an io.Reader.
+func NewGFFReader(r io.Reader) GFFReader {
+	return GFFReader{r: bufio.NewReader(r)}
+}
+
+func (r *GFFReader) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("GFF line %d: "+format,
+		append([]interface{}{r.line}, a...)...)
+}
+
+// ReadFeature returns a single feature on each call.
+//
+// Blank lines and comments are skipped.  A successful read is indicated
+// by err = nil for all features, including the last.  Subsequent calls
+// return err = io.EOF.
+func (r *GFFReader) ReadFeature() (f GFFFeature, err error) {
+	var line string
+	for {
+		if r.fasta {
+			return f, io.EOF
+		}
+		b, err := readLine(r.r)
+		if err != nil {
+			return f, err
+		}
+		r.line++
+		line = string(b)
+		switch {
+		case line == "##FASTA":
+			r.fasta = true
+			continue
+		case strings.HasPrefix(line, "##"):
+			r.Directives = append(r.Directives, line)
+			continue
+		case line == "" || line[0] == '#':
+			continue
+		}
+		break
+	}
+	c := strings.Split(line, "\t")
+	if len(c) != 9 {
+		return f, r.errorf("%d columns", len(c))
+	}
+	f.SeqID = gffUnescape(c[0])
+	f.Source = gffUnescape(c[1])
+	f.Type = gffUnescape(c[2])
+	if f.Start, err = strconv.Atoi(c[3]); err != nil {
+		return f, r.errorf("invalid start %s", c[3])
+	}
+	f.Start--
+	if f.End, err = strconv.Atoi(c[4]); err != nil {
+		return f, r.errorf("invalid end %s", c[4])
+	}
+	if f.Start < 0 || f.End < f.Start {
+		return f, r.errorf("invalid range %s..%s", c[3], c[4])
+	}
+	f.Score = math.NaN()
+	if c[5] != "." {
+		if f.Score, err = strconv.ParseFloat(c[5], 64); err != nil {
+			return f, r.errorf("invalid score %s", c[5])
+		}
+	}
+	if len(c[6]) != 1 || strings.IndexByte("+-.?", c[6][0]) < 0 {
+		return f, r.errorf("invalid strand %s", c[6])
+	}
+	f.Strand = c[6][0]
+	switch c[7] {
+	case ".":
+		f.Phase = -1
+	case "0", "1", "2":
+		f.Phase = int(c[7][0] - '0')
+	default:
+		return f, r.errorf("invalid phase %s", c[7])
+	}
+	if c[8] != "." && c[8] != "" {
+		for _, a := range strings.Split(c[8], ";") {
+			if a == "" {
+				continue // allow trailing ;
+			}
+			eq := strings.IndexByte(a, '=')
+			if eq < 0 {
+				return f, r.errorf("invalid attribute %s", a)
+			}
+			v := strings.Split(a[eq+1:], ",")
+			for i := range v {
+				v[i] = gffUnescape(v[i])
+			}
+			f.Attributes = append(f.Attributes,
+				GFFAttribute{gffUnescape(a[:eq]), v})
+		}
+	}
+	return f, nil
+}
+
+// FASTA reads the sequences following a ##FASTA directive.
+//
+// It should be called after ReadFeature has returned io.EOF.  If there was
+// no ##FASTA directive it returns no sequences.
+func (r *GFFReader) FASTA() ([]FASTASeq, error) {
+	if !r.fasta {
+		return nil, nil
+	}
+	return ReadFASTA(r.r)
+}
+
+// ReadGFF reads all features from a GFF3 stream.
+func ReadGFF(r io.Reader) (f []GFFFeature, err error) {
+	gr := NewGFFReader(r)
+	for {
+		g, err := gr.ReadFeature()
+		switch {
+		case err == io.EOF:
+			return f, nil
+		case err != nil:
+			return nil, err
+		}
+		f = append(f, g)
+	}
+}
+
+// gffUnescape decodes %XX escapes.  Invalid escapes are left as they are.
+func gffUnescape(s string) string {
+	if strings.IndexByte(s, '%') < 0 {
+		return s
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				b = append(b, byte(n))
+				i += 2
+				continue
+			}
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
+
+// gffEscape encodes characters not allowed literally in GFF3 columns.
+// Argument special lists additional characters to encode.
+func gffEscape(s, special string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < ' ' || c == 0x7f || c == '%' || strings.IndexByte(special, c) >= 0 {
+			b = append(b, fmt.Sprintf("%%%02X", c)...)
+		} else {
+			b = append(b, c)
+		}
+	}
+	return string(b)
+}
+
+// GFFWriter writes GFF3.
+//
+// Output is buffered.  Call Flush after the last feature is written.
+type GFFWriter struct {
+	w      *bufio.Writer
+	header bool // version directive written
+}
+
+// NewGFFWriter constructs a GFFWriter around an io.Writer.
+//
+// A ##gff-version 3 directive is written before the first feature.
+func NewGFFWriter(w io.Writer) *GFFWriter {
+	return &GFFWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteFeature writes a single feature.
+//
+// Coordinates are converted back to 1-based closed coordinates.  Columns
+// are percent-encoded as needed.
+func (w *GFFWriter) WriteFeature(f GFFFeature) error {
+	if !w.header {
+		w.w.WriteString("##gff-version 3\n")
+		w.header = true
+	}
+	score := "."
+	if !math.IsNaN(f.Score) {
+		score = strconv.FormatFloat(f.Score, 'g', -1, 64)
+	}
+	strand := "."
+	if f.Strand != 0 {
+		strand = string(f.Strand)
+	}
+	phase := "."
+	if f.Phase >= 0 {
+		phase = strconv.Itoa(f.Phase)
+	}
+	fmt.Fprintf(w.w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\t",
+		gffEscape(f.SeqID, ""), gffEscape(f.Source, ""),
+		gffEscape(f.Type, ""), f.Start+1, f.End, score, strand, phase)
+	if len(f.Attributes) == 0 {
+		w.w.WriteByte('.')
+	}
+	for i, a := range f.Attributes {
+		if i > 0 {
+			w.w.WriteByte(';')
+		}
+		w.w.WriteString(gffEscape(a.Tag, ";=&,"))
+		w.w.WriteByte('=')
+		for j, v := range a.Values {
+			if j > 0 {
+				w.w.WriteByte(',')
+			}
+			w.w.WriteString(gffEscape(v, ";=&,"))
+		}
+	}
+	return w.w.WriteByte('\n')
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *GFFWriter) Flush() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,74 @@
+package bio_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/soniakeys/bio"
+)
+
+const gffTestData = `##gff-version 3
+##sequence-region ctg1 1 30
+ctg1	test	gene	3	20	.	+	.	ID=gene1;Name=abc%3Bdef
+ctg1	test	mRNA	3	20	.	+	.	ID=mrna1;Parent=gene1
+ctg1	test	CDS	21	26	4.5	-	0	ID=cds2;Note=a,b
+##FASTA
+>ctg1
+ccATGAAACGTTTTTGGGGGTCATTTTTTT
+`
+
+func ExampleGFFReader() {
+	r := bio.NewGFFReader(strings.NewReader(gffTestData))
+	for {
+		f, err := r.ReadFeature()
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		fmt.Println(f.Type, f.Start, f.End, f.Score, string(f.Strand),
+			f.Phase, f.Attributes)
+	}
+	fmt.Println(r.Directives)
+	s, err := r.FASTA()
+	fmt.Println(len(s), err)
+	// Output:
+	// gene 2 20 NaN + -1 [{ID [gene1]} {Name [abc;def]}]
+	// mRNA 2 20 NaN + -1 [{ID [mrna1]} {Parent [gene1]}]
+	// CDS 20 26 4.5 - 0 [{ID [cds2]} {Note [a b]}]
+	// EOF
+	// [##gff-version 3 ##sequence-region ctg1 1 30]
+	// 1 <nil>
+}
+
+func ExampleGFFFeature_Extract() {
+	r := bio.NewGFFReader(strings.NewReader(gffTestData))
+	var f []bio.GFFFeature
+	for {
+		g, err := r.ReadFeature()
+		if err != nil {
+			break
+		}
+		f = append(f, g)
+	}
+	s, _ := r.FASTA()
+	fmt.Println(f[0].Extract(s[0]))
+	fmt.Println(f[2].Extract(s[0]))
+	// Output:
+	// ATGAAACGTTTTTGGGGG <nil>
+	// AAATGA <nil>
+}
+
+func ExampleGFFWriter() {
+	f, _ := bio.ReadGFF(strings.NewReader(gffTestData))
+	w := bio.NewGFFWriter(os.Stdout)
+	for _, g := range f {
+		w.WriteFeature(g)
+	}
+	w.Flush()
+	// Output:
+	// ##gff-version 3
+	// ctg1	test	gene	3	20	.	+	.	ID=gene1;Name=abc%3Bdef
+	// ctg1	test	mRNA	3	20	.	+	.	ID=mrna1;Parent=gene1
+	// ctg1	test	CDS	21	26	4.5	-	0	ID=cds2;Note=a,b
+}
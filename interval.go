@@ -0,0 +1,154 @@
+package bio
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Interval is a range of sequence positions.
+//
+// Start is 0-based and the range is half-open, as with Go slice expressions.
+type Interval struct {
+	Start, End int
+}
+
+// Overlaps returns true if the receiver and iv share at least one position.
+func (r Interval) Overlaps(iv Interval) bool {
+	return r.Start < iv.End && iv.Start < r.End
+}
+
+// IntervalTree supports overlap queries on a static set of intervals.
+//
+// The intervals are held sorted by start position as an implicit balanced
+// binary tree.  Each node is augmented with the maximum end position of its
+// subtree.  Construction is O(n log n) and a query is O(log n + k) where
+// k is the number of intervals found.
+type IntervalTree struct {
+	iv     []Interval // sorted by Start
+	idx    []int      // index of iv[i] in the constructor argument
+	maxEnd []int      // max End of subtree rooted at i
+}
+
+// NewIntervalTree constructs an IntervalTree.
+//
+// Query results are indexes into the argument slice iv.
+func NewIntervalTree(iv []Interval) *IntervalTree {
+	t := &IntervalTree{
+		iv:     append([]Interval{}, iv...),
+		idx:    make([]int, len(iv)),
+		maxEnd: make([]int, len(iv)),
+	}
+	for i := range t.idx {
+		t.idx[i] = i
+	}
+	sort.Sort(ivSort{t})
+	t.augment(0, len(iv))
+	return t
+}
+
+type ivSort struct{ t *IntervalTree }
+
+func (s ivSort) Len() int { return len(s.t.iv) }
+func (s ivSort) Less(i, j int) bool {
+	a, b := s.t.iv[i], s.t.iv[j]
+	return a.Start < b.Start || a.Start == b.Start && s.t.idx[i] < s.t.idx[j]
+}
+func (s ivSort) Swap(i, j int) {
+	s.t.iv[i], s.t.iv[j] = s.t.iv[j], s.t.iv[i]
+	s.t.idx[i], s.t.idx[j] = s.t.idx[j], s.t.idx[i]
+}
+
+// augment computes maxEnd for the subtree of range lo:hi, returning the
+// result for the root.
+func (t *IntervalTree) augment(lo, hi int) int {
+	if lo == hi {
+		return -1 << 62
+	}
+	m := int(uint(lo+hi) >> 1)
+	e := t.iv[m].End
+	if l := t.augment(lo, m); l > e {
+		e = l
+	}
+	if r := t.augment(m+1, hi); r > e {
+		e = r
+	}
+	t.maxEnd[m] = e
+	return e
+}
+
+// Len returns the number of intervals in the tree.
+func (t *IntervalTree) Len() int { return len(t.iv) }
+
+// Overlap returns indexes of intervals overlapping the query interval q.
+//
+// Results are in order of interval start position.
+func (t *IntervalTree) Overlap(q Interval) (r []int) {
+	var f func(lo, hi int)
+	f = func(lo, hi int) {
+		if lo == hi {
+			return
+		}
+		m := int(uint(lo+hi) >> 1)
+		if t.maxEnd[m] <= q.Start {
+			return // nothing in this subtree ends late enough
+		}
+		f(lo, m)
+		if t.iv[m].Start >= q.End {
+			return // this and all to the right start too late
+		}
+		if t.iv[m].Overlaps(q) {
+			r = append(r, t.idx[m])
+		}
+		f(m+1, hi)
+	}
+	f(0, len(t.iv))
+	return
+}
+
+// Contain returns indexes of intervals containing position p.
+//
+// Results are in order of interval start position.
+func (t *IntervalTree) Contain(p int) []int {
+	return t.Overlap(Interval{p, p + 1})
+}
+
+// extract concatenates regions of a reference sequence, reverse
+// complementing the result if strand is '-'.  Function seq returns
+// a region of the reference.
+func extract(regions []Interval, strand byte, seq func(start, end int) (Seq, error)) (DNA8, error) {
+	var r DNA8
+	for _, iv := range regions {
+		s, err := seq(iv.Start, iv.End)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, s...)
+	}
+	if strand == '-' {
+		return r.ReverseComplement(), nil
+	}
+	return r, nil
+}
+
+// fastaRegion returns a function for extract that slices the sequence
+// of s.  The ID of s must match name.
+func fastaRegion(s FASTASeq, name string) func(start, end int) (Seq, error) {
+	return func(start, end int) (Seq, error) {
+		if id := s.ID(); id != name {
+			return nil, fmt.Errorf("sequence %s does not match %s", id, name)
+		}
+		if start < 0 || end > len(s.Seq) || start > end {
+			return nil, fmt.Errorf("range %d:%d out of bounds for %s",
+				start, end, name)
+		}
+		return s.Seq[start:end], nil
+	}
+}
+
+// indexedRegion returns a function for extract that reads the named
+// sequence of an IndexedFASTA.
+func indexedRegion(x *IndexedFASTA, name string) func(start, end int) (Seq, error) {
+	return func(start, end int) (Seq, error) {
+		return x.Seq(name, start, end)
+	}
+}
@@ -0,0 +1,46 @@
+package bio_test
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleIntervalTree() {
+	iv := []bio.Interval{{10, 20}, {0, 5}, {15, 40}, {30, 35}}
+	t := bio.NewIntervalTree(iv)
+	fmt.Println(t.Overlap(bio.Interval{18, 31}))
+	fmt.Println(t.Contain(5))
+	// Output:
+	// [0 2 3]
+	// []
+}
+
+func TestIntervalTree(t *testing.T) {
+	iv := make([]bio.Interval, 500)
+	for i := range iv {
+		s := rand.Intn(1000)
+		iv[i] = bio.Interval{s, s + rand.Intn(50)}
+	}
+	tr := bio.NewIntervalTree(iv)
+	for n := 0; n < 200; n++ {
+		s := rand.Intn(1100) - 50
+		q := bio.Interval{s, s + rand.Intn(30)}
+		got := map[int]bool{}
+		for _, i := range tr.Overlap(q) {
+			got[i] = true
+		}
+		want := map[int]bool{}
+		for i, r := range iv {
+			if r.Overlaps(q) {
+				want[i] = true
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("query %v: got %v, want %v", q, got, want)
+		}
+	}
+}
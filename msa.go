@@ -0,0 +1,473 @@
+package bio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MSA represents a multiple sequence alignment.
+//
+// Rows are aligned sequences, all of the same length, with gaps typically
+// represented by GapSymbol.  IDs holds an identifier for each row.
+// GC holds per-column annotation such as the SS_cons secondary structure
+// line of Stockholm files, keyed by feature name.
+type MSA struct {
+	IDs  []string
+	Rows []Seq
+	GC   map[string]Seq
+}
+
+// Add appends a row to the alignment.
+func (m *MSA) Add(id string, s Seq) {
+	m.IDs = append(m.IDs, id)
+	m.Rows = append(m.Rows, s)
+}
+
+// Width returns the number of columns of the alignment.
+func (m *MSA) Width() int {
+	if len(m.Rows) == 0 {
+		return 0
+	}
+	return len(m.Rows[0])
+}
+
+// Validate checks that the alignment is well formed, with an ID for each
+// row and all rows and GC annotation of the same length.
+func (m *MSA) Validate() error {
+	if len(m.IDs) != len(m.Rows) {
+		return fmt.Errorf("MSA: %d IDs for %d rows", len(m.IDs), len(m.Rows))
+	}
+	w := m.Width()
+	for i, r := range m.Rows {
+		if len(r) != w {
+			return fmt.Errorf("MSA: row %s length %d, expected %d",
+				m.IDs[i], len(r), w)
+		}
+	}
+	for f, a := range m.GC {
+		if len(a) != w {
+			return fmt.Errorf("MSA: GC %s length %d, expected %d", f, len(a), w)
+		}
+	}
+	return nil
+}
+
+// Column returns column i of the alignment, with one symbol for each row.
+func (m *MSA) Column(i int) Seq {
+	c := make(Seq, len(m.Rows))
+	for j, r := range m.Rows {
+		c[j] = r[i]
+	}
+	return c
+}
+
+// Slice returns the alignment of columns start:end.
+//
+// Rows and GC annotation of the result are slices of those of the receiver.
+func (m *MSA) Slice(start, end int) *MSA {
+	s := &MSA{IDs: m.IDs, Rows: make([]Seq, len(m.Rows))}
+	for i, r := range m.Rows {
+		s.Rows[i] = r[start:end]
+	}
+	if m.GC != nil {
+		s.GC = map[string]Seq{}
+		for f, a := range m.GC {
+			s.GC[f] = a[start:end]
+		}
+	}
+	return s
+}
+
+// CountProfile constructs a CountProfile from the rows of a DNA alignment.
+//
+// Gaps and other non-DNA symbols are ignored, as with CountProfile.Add.
+func (m *MSA) CountProfile() CountProfile {
+	p := make(CountProfile, m.Width())
+	for _, r := range m.Rows {
+		p.Add(DNA(r))
+	}
+	return p
+}
+
+// FracProfile constructs a FracProfile from the rows of a DNA alignment.
+//
+// Fractions are of the DNA bases in each column, so that gaps and other
+// non-DNA symbols are ignored.  For each column with at least one base,
+// fractions sum to approximately 1.0.  Columns with no bases are all zero.
+func (m *MSA) FracProfile() FracProfile {
+	c := m.CountProfile()
+	p := make(FracProfile, len(c))
+	for i, n := range c {
+		t := n[0] + n[1] + n[2] + n[3]
+		if t == 0 {
+			continue
+		}
+		for j, f := range n {
+			p[i][j] = float64(f) / float64(t)
+		}
+	}
+	return p
+}
+
+// msaReader wraps a bufio.Reader with line counting for the MSA readers.
+type msaReader struct {
+	r    *bufio.Reader
+	line int
+	fmt  string // format name for errors
+}
+
+func newMSAReader(r io.Reader, format string) *msaReader {
+	return &msaReader{r: bufio.NewReader(r), fmt: format}
+}
+
+// next returns the next line, with trailing white space removed.
+func (r *msaReader) next() (string, error) {
+	b, err := readLine(r.r)
+	if err != nil {
+		return "", err
+	}
+	r.line++
+	return strings.TrimRight(string(b), " \t\r"), nil
+}
+
+func (r *msaReader) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("%s line %d: "+format,
+		append([]interface{}{r.fmt, r.line}, a...)...)
+}
+
+// blockRows accumulates rows of formats where rows are split over blocks
+// and each block line starts with the row ID.
+type blockRows struct {
+	m   *MSA
+	row map[string]int
+}
+
+func (b *blockRows) add(id, s string) {
+	if b.row == nil {
+		b.row = map[string]int{}
+	}
+	i, ok := b.row[id]
+	if !ok {
+		i = len(b.m.Rows)
+		b.row[id] = i
+		b.m.Add(id, nil)
+	}
+	b.m.Rows[i] = append(b.m.Rows[i], s...)
+}
+
+// ReadClustal reads an alignment in Clustal format.
+//
+// The first line must start with "CLUSTAL".  Conservation lines and
+// optional sequence position numbers at line ends are ignored.
+func ReadClustal(r io.Reader) (*MSA, error) {
+	mr := newMSAReader(r, "Clustal")
+	line, err := mr.next()
+	if err != nil || !strings.HasPrefix(line, "CLUSTAL") {
+		return nil, errors.New("Clustal: missing CLUSTAL header")
+	}
+	b := blockRows{m: &MSA{}}
+	for {
+		line, err := mr.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == "" || line[0] == ' ' {
+			continue // blank or conservation line
+		}
+		f := strings.Fields(line)
+		switch len(f) {
+		case 3:
+			if _, err := strconv.Atoi(f[2]); err != nil {
+				return nil, mr.errorf("invalid line")
+			}
+		case 2:
+		default:
+			return nil, mr.errorf("invalid line")
+		}
+		b.add(f[0], f[1])
+	}
+	if err := b.m.Validate(); err != nil {
+		return nil, err
+	}
+	return b.m, nil
+}
+
+// idWidth returns the width to pad IDs to, at least min.
+func (m *MSA) idWidth(min int) int {
+	for _, id := range m.IDs {
+		if len(id) >= min {
+			min = len(id) + 1
+		}
+	}
+	return min
+}
+
+// WriteClustal writes an alignment in Clustal format with 60 columns per
+// block.
+//
+// Conservation lines mark columns where all rows have the same non-gap
+// symbol with '*'.
+func WriteClustal(w io.Writer, m *MSA) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("CLUSTAL W multiple sequence alignment\n")
+	iw := m.idWidth(16)
+	for start := 0; start < m.Width(); start += 60 {
+		end := start + 60
+		if end > m.Width() {
+			end = m.Width()
+		}
+		bw.WriteByte('\n')
+		for i, r := range m.Rows {
+			fmt.Fprintf(bw, "%-*s%s\n", iw, m.IDs[i], r[start:end])
+		}
+		cons := make([]byte, end-start)
+		for c := start; c < end; c++ {
+			cons[c-start] = ' '
+			col := m.Column(c)
+			if col[0] != GapSymbol && strings.Count(string(col),
+				string(col[:1])) == len(col) {
+				cons[c-start] = '*'
+			}
+		}
+		fmt.Fprintf(bw, "%*s%s\n", iw, "", cons)
+	}
+	return bw.Flush()
+}
+
+// ReadStockholm reads an alignment in Stockholm format.
+//
+// Only the first alignment of the stream is read.  #=GC annotation is
+// stored in MSA.GC.  Other markup lines are ignored.
+func ReadStockholm(r io.Reader) (*MSA, error) {
+	mr := newMSAReader(r, "Stockholm")
+	line, err := mr.next()
+	if err != nil || !strings.HasPrefix(line, "# STOCKHOLM") {
+		return nil, errors.New("Stockholm: missing # STOCKHOLM header")
+	}
+	b := blockRows{m: &MSA{}}
+	for {
+		line, err := mr.next()
+		if err == io.EOF {
+			return nil, mr.errorf("unexpected EOF, no //")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case line == "//":
+			if err := b.m.Validate(); err != nil {
+				return nil, err
+			}
+			return b.m, nil
+		case strings.HasPrefix(line, "#=GC "):
+			f := strings.Fields(line)
+			if len(f) != 3 {
+				return nil, mr.errorf("invalid #=GC line")
+			}
+			if b.m.GC == nil {
+				b.m.GC = map[string]Seq{}
+			}
+			b.m.GC[f[1]] = append(b.m.GC[f[1]], f[2]...)
+		case line == "" || line[0] == '#':
+		default:
+			f := strings.Fields(line)
+			if len(f) != 2 {
+				return nil, mr.errorf("invalid line")
+			}
+			b.add(f[0], f[1])
+		}
+	}
+}
+
+// WriteStockholm writes an alignment in Stockholm format, each row on
+// a single line.
+//
+// GC annotation is written in order of feature name.
+func WriteStockholm(w io.Writer, m *MSA) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	var gc []string
+	for f := range m.GC {
+		gc = append(gc, f)
+	}
+	sort.Strings(gc)
+	iw := m.idWidth(0)
+	for _, f := range gc {
+		if n := len("#=GC ") + len(f) + 1; n > iw {
+			iw = n
+		}
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("# STOCKHOLM 1.0\n\n")
+	for i, r := range m.Rows {
+		fmt.Fprintf(bw, "%-*s%s\n", iw, m.IDs[i], r)
+	}
+	for _, f := range gc {
+		fmt.Fprintf(bw, "%-*s%s\n", iw, "#=GC "+f, m.GC[f])
+	}
+	bw.WriteString("//\n")
+	return bw.Flush()
+}
+
+// ReadAlignedFASTA reads an alignment in FASTA format.
+//
+// IDs are taken as by FASTASeq.ID.  All sequences must be of the same
+// length.
+func ReadAlignedFASTA(r io.Reader) (*MSA, error) {
+	s, err := ReadFASTA(r)
+	if err != nil {
+		return nil, err
+	}
+	m := &MSA{}
+	for _, f := range s {
+		m.Add(f.ID(), f.Seq)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WriteAlignedFASTA writes an alignment in FASTA format.
+//
+// Argument width is the line width as for WriteFASTA.
+func WriteAlignedFASTA(w io.Writer, m *MSA, width int) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	fw := NewFASTAWriter(w)
+	fw.Width = width
+	for i, r := range m.Rows {
+		if err := fw.WriteRecord(m.IDs[i], "", r); err != nil {
+			return err
+		}
+	}
+	return fw.Flush()
+}
+
+// ReadPHYLIP reads an alignment in PHYLIP format, either sequential or
+// interleaved.
+//
+// Names are read in the relaxed style, separated from sequence data by
+// white space, and so can not contain spaces.  White space within sequence
+// data is ignored.  The layout is detected from the line structure.
+func ReadPHYLIP(r io.Reader) (*MSA, error) {
+	mr := newMSAReader(r, "PHYLIP")
+	line, err := mr.next()
+	if err != nil {
+		return nil, errors.New("PHYLIP: missing header")
+	}
+	var ntax, nchar int
+	if f := strings.Fields(line); len(f) < 2 {
+		return nil, errors.New("PHYLIP: invalid header")
+	} else if ntax, err = strconv.Atoi(f[0]); err == nil {
+		nchar, err = strconv.Atoi(f[1])
+	}
+	if err != nil || ntax < 0 || nchar < 0 {
+		return nil, errors.New("PHYLIP: invalid header")
+	}
+	var lines []string
+	for {
+		line, err := mr.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) < ntax || ntax == 0 && len(lines) > 0 {
+		return nil, fmt.Errorf("PHYLIP: %d lines for %d sequences",
+			len(lines), ntax)
+	}
+	nameSeq := func(line string) (string, string) {
+		f := strings.Fields(line)
+		return f[0], strings.Join(f[1:], "")
+	}
+	// try sequential
+	m := &MSA{}
+	l := lines
+	for len(m.Rows) < ntax && len(l) > 0 {
+		id, s := nameSeq(l[0])
+		l = l[1:]
+		for len(s) < nchar && len(l) > 0 {
+			s += strings.Join(strings.Fields(l[0]), "")
+			l = l[1:]
+		}
+		m.Add(id, Seq(s))
+	}
+	if len(l) == 0 && len(m.Rows) == ntax && m.Validate() == nil &&
+		m.Width() == nchar {
+		return m, nil
+	}
+	// interleaved
+	m = &MSA{}
+	for _, line := range lines[:ntax] {
+		id, s := nameSeq(line)
+		m.Add(id, Seq(s))
+	}
+	for i, line := range lines[ntax:] {
+		r := &m.Rows[i%ntax]
+		*r = append(*r, strings.Join(strings.Fields(line), "")...)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	if m.Width() != nchar {
+		return nil, fmt.Errorf("PHYLIP: %d columns, header says %d",
+			m.Width(), nchar)
+	}
+	return m, nil
+}
+
+// WritePHYLIP writes an alignment in PHYLIP format.
+//
+// Names are padded to at least 10 characters, longer names are written
+// in the relaxed style.  If interleaved is true, blocks of 60 columns
+// are written.  Otherwise each row is written on a single line.
+func WritePHYLIP(w io.Writer, m *MSA, interleaved bool) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%d %d\n", len(m.Rows), m.Width())
+	iw := m.idWidth(10)
+	bs := m.Width()
+	if interleaved {
+		bs = 60
+	}
+	for start := 0; start == 0 || start < m.Width(); start += bs {
+		end := start + bs
+		if end > m.Width() {
+			end = m.Width()
+		}
+		if start > 0 {
+			bw.WriteByte('\n')
+		}
+		for i, r := range m.Rows {
+			if start == 0 {
+				fmt.Fprintf(bw, "%-*s", iw, m.IDs[i])
+			}
+			fmt.Fprintf(bw, "%s\n", r[start:end])
+		}
+		if bs == 0 {
+			break
+		}
+	}
+	return bw.Flush()
+}
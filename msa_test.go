@@ -0,0 +1,158 @@
+package bio_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func testMSA() *bio.MSA {
+	m := &bio.MSA{}
+	m.Add("seq1", bio.Seq("ACGT-ACGTAACG"))
+	m.Add("seq2", bio.Seq("ACGTTACG--ACG"))
+	m.Add("seq3", bio.Seq("ACCT-ACGTAAC-"))
+	m.GC = map[string]bio.Seq{"SS_cons": bio.Seq("<<<...>>>....")}
+	return m
+}
+
+func ExampleMSA_Column() {
+	m := testMSA()
+	fmt.Println(m.Width())
+	fmt.Println(m.Column(2))
+	fmt.Println(m.Slice(3, 6).Rows)
+	// Output:
+	// 13
+	// GGC
+	// [T-A TTA T-A]
+}
+
+func ExampleMSA_CountProfile() {
+	m := testMSA()
+	fmt.Println(m.CountProfile()[:5])
+	fmt.Println(m.CountProfile().Consensus())
+	fmt.Println(m.FracProfile()[12])
+	// Output:
+	// [[3 0 0 0] [0 3 0 0] [0 1 0 2] [0 0 3 0] [0 0 1 0]]
+	// ACGTTACGTAACG 33
+	// [0 0 0 1]
+}
+
+func ExampleWriteClustal() {
+	bio.WriteClustal(os.Stdout, testMSA())
+	// Output:
+	// CLUSTAL W multiple sequence alignment
+	//
+	// seq1            ACGT-ACGTAACG
+	// seq2            ACGTTACG--ACG
+	// seq3            ACCT-ACGTAAC-
+	//                 ** * ***  **
+}
+
+func ExampleWriteStockholm() {
+	bio.WriteStockholm(os.Stdout, testMSA())
+	// Output:
+	// # STOCKHOLM 1.0
+	//
+	// seq1         ACGT-ACGTAACG
+	// seq2         ACGTTACG--ACG
+	// seq3         ACCT-ACGTAAC-
+	// #=GC SS_cons <<<...>>>....
+	// //
+}
+
+func ExampleWritePHYLIP() {
+	bio.WritePHYLIP(os.Stdout, testMSA(), false)
+	// Output:
+	// 3 13
+	// seq1      ACGT-ACGTAACG
+	// seq2      ACGTTACG--ACG
+	// seq3      ACCT-ACGTAAC-
+}
+
+func ExampleReadPHYLIP() {
+	interleaved := `3 13
+seq1 ACGT-A
+seq2 ACGTTA
+seq3 ACCT-A
+
+CGTAACG
+CG--ACG
+CGTAAC-
+`
+	m, err := bio.ReadPHYLIP(strings.NewReader(interleaved))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(m.IDs)
+	fmt.Println(m.Rows)
+	// Output:
+	// [seq1 seq2 seq3]
+	// [ACGT-ACGTAACG ACGTTACG--ACG ACCT-ACGTAAC-]
+}
+
+// TestMSARoundTrip writes and reads back each format.
+func TestMSARoundTrip(t *testing.T) {
+	m := testMSA()
+	// make rows long enough for multiple blocks
+	for i, r := range m.Rows {
+		m.Rows[i] = bytes.Repeat(r, 10)
+	}
+	m.GC["SS_cons"] = bytes.Repeat(m.GC["SS_cons"], 10)
+	noGC := &bio.MSA{IDs: m.IDs, Rows: m.Rows}
+	var b bytes.Buffer
+	for _, tc := range []struct {
+		name  string
+		write func() error
+		read  func() (*bio.MSA, error)
+		want  *bio.MSA
+	}{
+		{"Clustal",
+			func() error { return bio.WriteClustal(&b, m) },
+			func() (*bio.MSA, error) { return bio.ReadClustal(&b) }, noGC},
+		{"Stockholm",
+			func() error { return bio.WriteStockholm(&b, m) },
+			func() (*bio.MSA, error) { return bio.ReadStockholm(&b) }, m},
+		{"FASTA",
+			func() error { return bio.WriteAlignedFASTA(&b, m, 60) },
+			func() (*bio.MSA, error) { return bio.ReadAlignedFASTA(&b) }, noGC},
+		{"PHYLIP sequential",
+			func() error { return bio.WritePHYLIP(&b, m, false) },
+			func() (*bio.MSA, error) { return bio.ReadPHYLIP(&b) }, noGC},
+		{"PHYLIP interleaved",
+			func() error { return bio.WritePHYLIP(&b, m, true) },
+			func() (*bio.MSA, error) { return bio.ReadPHYLIP(&b) }, noGC},
+	} {
+		b.Reset()
+		if err := tc.write(); err != nil {
+			t.Fatal(tc.name, err)
+		}
+		got, err := tc.read()
+		if err != nil {
+			t.Fatal(tc.name, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestReadPHYLIPInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"2\n",
+		"x 5\n",
+		"2 5\nfoo ACGTA\n",
+		"0 5\nfoo ACGTA\n",
+		"2 5\nfoo ACGTA\nbar ACG\n",
+	} {
+		if _, err := bio.ReadPHYLIP(strings.NewReader(s)); err == nil {
+			t.Errorf("%q: no error", s)
+		}
+	}
+}
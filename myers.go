@@ -0,0 +1,164 @@
+package bio
+
+// Myers is a bit-parallel matcher for approximate string matching with
+// edit distance.
+//
+// Member P is the Seq passed to the constructor.  As with KMP, Myers
+// member functions will not work if the Seq is subsequently modified.
+// Comparison is byte-wise and so case sensitive.
+//
+// Reference: Myers 1999, "A fast bit-vector algorithm for approximate
+// string matching based on dynamic programming", J. ACM 46:395, with the
+// block extension of Hyyrö 2001.
+type Myers struct {
+	P   Seq
+	peq [256][]uint64 // match bit vectors by symbol, one word per block
+}
+
+// ApproxMatch is an approximate match of a pattern in a text.
+type ApproxMatch struct {
+	Start int // start of the match in the text, or -1 if not computed
+	End   int // end of the match in the text, exclusive
+	Dist  int // edit distance between the pattern and the match
+}
+
+// NewMyers creates a Myers matcher for pattern p.
+//
+// Patterns of any length are allowed.  Patterns longer than 64 symbols are
+// processed in blocks of 64.
+func NewMyers(p Seq) *Myers {
+	m := &Myers{P: p}
+	nb := (len(p) + 63) / 64
+	for i, b := range p {
+		if m.peq[b] == nil {
+			m.peq[b] = make([]uint64, nb)
+		}
+		m.peq[b][i/64] |= 1 << uint(i%64)
+	}
+	return m
+}
+
+// AllEnd finds all end positions in t of substrings within edit distance k
+// of the pattern.
+//
+// A result is returned for each end position with the minimum edit
+// distance of any substring ending there.  Start is -1 in the results.
+// See AllMatch to compute start positions.  Results are in order of
+// position.
+//
+// Time complexity is O(len(t) * ⌈len(P)/64⌉).
+func (m *Myers) AllEnd(t Seq, k int) (x []ApproxMatch) {
+	n := len(m.P)
+	if n == 0 || k < 0 {
+		return nil
+	}
+	nb := (n + 63) / 64
+	pv := make([]uint64, nb)
+	mv := make([]uint64, nb)
+	for i := range pv {
+		pv[i] = ^uint64(0)
+	}
+	zero := make([]uint64, nb)
+	last := uint64(1) << uint((n-1)%64)
+	score := n
+	for j, c := range t {
+		eqs := m.peq[c]
+		if eqs == nil {
+			eqs = zero
+		}
+		hin := 0 // horizontal delta into the top of the block
+		for b := 0; b < nb; b++ {
+			eq := eqs[b]
+			xv := eq | mv[b]
+			if hin < 0 {
+				eq |= 1
+			}
+			xh := ((eq & pv[b]) + pv[b]) ^ pv[b] | eq
+			ph := mv[b] | ^(xh | pv[b])
+			mh := pv[b] & xh
+			hbit := uint64(1) << 63
+			if b == nb-1 {
+				hbit = last
+			}
+			hout := 0
+			if ph&hbit != 0 {
+				hout = 1
+			} else if mh&hbit != 0 {
+				hout = -1
+			}
+			ph <<= 1
+			mh <<= 1
+			if hin < 0 {
+				mh |= 1
+			} else if hin > 0 {
+				ph |= 1
+			}
+			pv[b] = mh | ^(xv | ph)
+			mv[b] = ph & xv
+			hin = hout
+		}
+		score += hin
+		if score <= k {
+			x = append(x, ApproxMatch{-1, j + 1, score})
+		}
+	}
+	return
+}
+
+// AllMatch finds all matches in t within edit distance k of the pattern,
+// computing start positions.
+//
+// Matches are those of AllEnd.  For each, the start is found by traceback
+// of a dynamic programming alignment ending at the end position.  Where
+// several starts give the same minimum edit distance, the start of the
+// shortest match is returned.
+func (m *Myers) AllMatch(t Seq, k int) []ApproxMatch {
+	x := m.AllEnd(t, k)
+	p := m.P
+	n := len(p)
+	prev := make([]int, n+1)
+	cur := make([]int, n+1)
+	for i := range x {
+		e := x[i].End
+		if x[i].Dist == n {
+			// empty match
+			x[i].Start = e
+			continue
+		}
+		lo := e - n - x[i].Dist
+		if lo < 0 {
+			lo = 0
+		}
+		// Align reversed pattern to text read backward from e.  Column
+		// j has consumed j text symbols.  cur[r] is the distance of the
+		// last r pattern symbols.
+		for r := range prev {
+			prev[r] = r
+		}
+		best := 0
+		for j := 1; j <= e-lo; j++ {
+			c := t[e-j]
+			cur[0] = j
+			for r := 1; r <= n; r++ {
+				d := prev[r-1]
+				if p[n-r] != c {
+					d++
+				}
+				if prev[r]+1 < d {
+					d = prev[r] + 1
+				}
+				if cur[r-1]+1 < d {
+					d = cur[r-1] + 1
+				}
+				cur[r] = d
+			}
+			prev, cur = cur, prev
+			if prev[n] == x[i].Dist {
+				best = j
+				break
+			}
+		}
+		x[i].Start = e - best
+	}
+	return x
+}
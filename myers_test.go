@@ -0,0 +1,123 @@
+package bio_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleMyers_AllEnd() {
+	m := bio.NewMyers(bio.Seq("GATTACA"))
+	for _, x := range m.AllEnd(bio.Seq("TTGATACAGGGATTTACAT"), 1) {
+		fmt.Println(x.End, x.Dist)
+	}
+	// Output:
+	// 8 1
+	// 18 1
+}
+
+func ExampleMyers_AllMatch() {
+	m := bio.NewMyers(bio.Seq("GATTACA"))
+	t := bio.Seq("TTGATACAGGGATTTACAT")
+	for _, x := range m.AllMatch(t, 1) {
+		fmt.Println(x.Start, x.End, x.Dist, t[x.Start:x.End])
+	}
+	// Output:
+	// 2 8 1 GATACA
+	// 10 18 1 GATTTACA
+}
+
+// editEnd computes by dynamic programming the minimum edit distance
+// between p and substrings of t ending at each position of t.
+func editEnd(p, t bio.Seq) []int {
+	col := make([]int, len(p)+1)
+	for i := range col {
+		col[i] = i
+	}
+	d := make([]int, len(t))
+	for j, c := range t {
+		diag := col[0]
+		col[0] = 0
+		for i := 1; i <= len(p); i++ {
+			v := diag
+			if p[i-1] != c {
+				v++
+			}
+			if col[i]+1 < v {
+				v = col[i] + 1
+			}
+			if col[i-1]+1 < v {
+				v = col[i-1] + 1
+			}
+			diag, col[i] = col[i], v
+		}
+		d[j] = col[len(p)]
+	}
+	return d
+}
+
+// editDist computes the edit distance between s and t.
+func editDist(s, t bio.Seq) int {
+	col := make([]int, len(s)+1)
+	for i := range col {
+		col[i] = i
+	}
+	for j, c := range t {
+		diag := col[0]
+		col[0] = j + 1
+		for i := 1; i <= len(s); i++ {
+			v := diag
+			if s[i-1] != c {
+				v++
+			}
+			if col[i]+1 < v {
+				v = col[i] + 1
+			}
+			if col[i-1]+1 < v {
+				v = col[i-1] + 1
+			}
+			diag, col[i] = col[i], v
+		}
+	}
+	return col[len(s)]
+}
+
+func TestMyers(t *testing.T) {
+	rs := func(n int) bio.Seq {
+		s := make(bio.Seq, n)
+		for i := range s {
+			s[i] = "ACGT"[rand.Intn(4)]
+		}
+		return s
+	}
+	for _, n := range []int{1, 5, 63, 64, 65, 130} {
+		p := rs(n)
+		s := rs(400)
+		// plant mutated copies
+		for i := 0; i < 3; i++ {
+			q := append(bio.Seq{}, p...)
+			q[rand.Intn(n)] = 'A'
+			copy(s[rand.Intn(len(s)-n):], q)
+		}
+		m := bio.NewMyers(p)
+		k := n / 5
+		d := editEnd(p, s)
+		var want []bio.ApproxMatch
+		for j, dj := range d {
+			if dj <= k {
+				want = append(want, bio.ApproxMatch{-1, j + 1, dj})
+			}
+		}
+		got := m.AllEnd(s, k)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("n %d: got %v, want %v", n, got, want)
+		}
+		for _, x := range m.AllMatch(s, k) {
+			if dd := editDist(p, s[x.Start:x.End]); dd != x.Dist {
+				t.Fatalf("n %d: match %v has distance %d", n, x, dd)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package bio
+
+// ORF is an open reading frame located by DNA8.FindORFs.
+type ORF struct {
+	Strand  byte // '+' or '-'
+	Frame   int  // 0, 1, or 2, the offset of the first codon on the strand
+	Start   int  // start position on the forward strand, 0-based
+	End     int  // end position on the forward strand, exclusive
+	Peptide AA20 // translation, not including the stop
+	Stop    bool // true if the ORF ends with a stop codon
+}
+
+// ORFOptions control DNA8.FindORFs.
+type ORFOptions struct {
+	// MinLen is the minimum length of the peptide in amino acids.
+	MinLen int
+	// AllStarts reports an ORF for every start codon.  Otherwise only the
+	// longest ORF, beginning at the first start codon after a stop, is
+	// reported.
+	AllStarts bool
+	// Partial reports ORFs that run off the 3' end of the sequence
+	// without a stop codon.
+	Partial bool
+	// Code is the genetic code.  If nil, the standard code is used.
+	Code *GeneticCode
+}
+
+// FindORFs locates open reading frames on both strands of a sequence.
+//
+// An ORF begins with a start codon of the genetic code and extends through
+// the next in-frame stop codon.  The start codon is translated as M even
+// if it is an alternative start codon.
+//
+// Start and End of the results are forward strand coordinates, including
+// the stop codon if present.  For ORFs on the '-' strand, Start is thus the
+// position of the stop codon and End is just past the start codon.  Frame
+// is relative to the start of the strand, so for the '-' strand it is
+// relative to the end of the receiver sequence.
+//
+// Results are ordered by strand, '+' first, then frame, then position
+// along the strand.
+func (s DNA8) FindORFs(opt ORFOptions) (r []ORF) {
+	c := opt.Code
+	if c == nil {
+		c = StandardCode
+	}
+	n := len(s)
+	for _, strand := range []byte{'+', '-'} {
+		t := s
+		if strand == '-' {
+			t = s.ReverseComplement()
+		}
+		for f := 0; f < 3; f++ {
+			var starts []int // pending start positions on t
+			emit := func(end int, stop bool) {
+				for _, st := range starts {
+					o := ORF{Strand: strand, Frame: f, Start: st, End: end,
+						Stop: stop}
+					pe := end
+					if stop {
+						pe -= 3
+					}
+					if (pe-st)/3 < opt.MinLen {
+						continue
+					}
+					o.Peptide, _ = translateCode(t[st:pe], c)
+					o.Peptide[0] = 'M'
+					if strand == '-' {
+						o.Start, o.End = n-end, n-st
+					}
+					r = append(r, o)
+				}
+				starts = starts[:0]
+			}
+			p := f
+			for ; p+3 <= n; p += 3 {
+				if c.TranslateCodon(t[p], t[p+1], t[p+2]) == AAStop {
+					emit(p+3, true)
+					continue
+				}
+				if c.IsStartCodon(t[p], t[p+1], t[p+2]) &&
+					(opt.AllStarts || len(starts) == 0) {
+					starts = append(starts, p)
+				}
+			}
+			if opt.Partial {
+				emit(p, false)
+			}
+		}
+	}
+	return
+}
+
+// SixFrame translates a sequence in all six reading frames.
+//
+// Results are the frames 0, 1, 2 of the forward strand followed by frames
+// 0, 1, 2 of the reverse complement.  Translation continues through stop
+// codons, which appear in the result as AAStop.  Incomplete codons at the
+// end of a frame are ignored.  If c is nil, the standard code is used.
+func (s DNA8) SixFrame(c *GeneticCode) (f [6]AA20) {
+	if c == nil {
+		c = StandardCode
+	}
+	rc := s.ReverseComplement()
+	for i, t := range []DNA8{s, rc} {
+		for fr := 0; fr < 3; fr++ {
+			var a AA20
+			for p := fr; p+3 <= len(t); p += 3 {
+				a = append(a, c.TranslateCodon(t[p], t[p+1], t[p+2]))
+			}
+			f[i*3+fr] = a
+		}
+	}
+	return
+}
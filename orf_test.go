@@ -0,0 +1,41 @@
+package bio_test
+
+import (
+	"fmt"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleDNA8_FindORFs() {
+	s := bio.DNA8("CCATGAAAATGTTTTAGGGCTACATTTTA")
+	for _, o := range s.FindORFs(bio.ORFOptions{}) {
+		fmt.Printf("%c %d %d %d %s %t\n",
+			o.Strand, o.Frame, o.Start, o.End, o.Peptide, o.Stop)
+	}
+	fmt.Println()
+	for _, o := range s.FindORFs(bio.ORFOptions{AllStarts: true, Partial: true}) {
+		fmt.Printf("%c %d %d %d %s %t\n",
+			o.Strand, o.Frame, o.Start, o.End, o.Peptide, o.Stop)
+	}
+	// Output:
+	// + 2 2 17 MKMF true
+	// - 1 19 25 M true
+	//
+	// + 2 2 17 MKMF true
+	// + 2 8 17 MF true
+	// - 1 19 25 M true
+	// - 1 1 4 M false
+}
+
+func ExampleDNA8_SixFrame() {
+	for _, f := range bio.DNA8("CCATGAAAATGTTTTAG").SixFrame(nil) {
+		fmt.Println(f)
+	}
+	// Output:
+	// P*KCF
+	// HENVL
+	// MKMF*
+	// LKHFH
+	// *NIFM
+	// KTFSW
+}
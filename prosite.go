@@ -0,0 +1,325 @@
+package bio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PrositePattern is a compiled PROSITE pattern.
+//
+// Member Pattern is the pattern string passed to ParseProsite.
+type PrositePattern struct {
+	Pattern string
+	nterm   bool // anchored at the N-terminus
+	cterm   bool // anchored at the C-terminus
+	el      []prositeElem
+}
+
+// prositeElem is a pattern element, a set of residues with a repeat range.
+type prositeElem struct {
+	set      uint32 // residues, bit n for 'A'+n
+	min, max int
+	end      bool // element may match the C-terminus, as in [G>]
+}
+
+const prositeAny = 1<<26 - 1
+
+func (e *prositeElem) has(b byte) bool {
+	return b >= 'A' && b <= 'Z' && e.set>>(b-'A')&1 != 0
+}
+
+// ParseProsite parses a pattern in PROSITE syntax.
+//
+// Elements are separated by '-'.  An element is a residue symbol, x for
+// any residue, a set of residues in square brackets, or a set of excluded
+// residues in braces.  An element may be followed by a repeat count in
+// parentheses, either (n) or (n,m).  A leading '<' anchors the pattern at
+// the N-terminus and a trailing '>' anchors it at the C-terminus.  A '>'
+// within square brackets of the last element allows the element to match
+// the C-terminus.  A trailing period is optional.
+//
+// Example: "C-x(2,4)-C-x(3)-[LIVMFYWC]-x(8)-H-x(3,5)-H."
+func ParseProsite(pat string) (*PrositePattern, error) {
+	m := &PrositePattern{Pattern: pat}
+	errorf := func(format string, a ...interface{}) error {
+		return fmt.Errorf("PROSITE pattern %q: "+format,
+			append([]interface{}{pat}, a...)...)
+	}
+	p := strings.TrimSuffix(strings.TrimSpace(pat), ".")
+	if strings.HasPrefix(p, "<") {
+		m.nterm = true
+		p = p[1:]
+	}
+	if strings.HasSuffix(p, ">") {
+		m.cterm = true
+		p = p[:len(p)-1]
+	}
+	if p == "" {
+		return nil, errorf("empty pattern")
+	}
+	for _, f := range strings.Split(p, "-") {
+		e := prositeElem{min: 1, max: 1}
+		if f == "" {
+			return nil, errorf("empty element")
+		}
+		// residues
+		var i int
+		switch c := f[0]; {
+		case c == 'x' || c == 'X':
+			e.set = prositeAny
+			i = 1
+		case c == '[' || c == '{':
+			close := byte(']')
+			if c == '{' {
+				close = '}'
+			}
+			j := strings.IndexByte(f, close)
+			if j < 0 {
+				return nil, errorf("unclosed %c", c)
+			}
+			for _, b := range []byte(f[1:j]) {
+				switch {
+				case b >= 'A' && b <= 'Z':
+					e.set |= 1 << (b - 'A')
+				case b == '>' && c == '[':
+					e.end = true
+				default:
+					return nil, errorf("invalid symbol %q", b)
+				}
+			}
+			if c == '{' {
+				e.set = prositeAny &^ e.set
+			}
+			i = j + 1
+		case c >= 'A' && c <= 'Z':
+			e.set = 1 << (c - 'A')
+			i = 1
+		default:
+			return nil, errorf("invalid element %q", f)
+		}
+		// repeat count
+		if r := f[i:]; r != "" {
+			if r[0] != '(' || r[len(r)-1] != ')' {
+				return nil, errorf("invalid element %q", f)
+			}
+			n := strings.Split(r[1:len(r)-1], ",")
+			var err error
+			if e.min, err = strconv.Atoi(n[0]); err != nil || len(n) > 2 {
+				return nil, errorf("invalid repeat %q", r)
+			}
+			e.max = e.min
+			if len(n) == 2 {
+				if e.max, err = strconv.Atoi(n[1]); err != nil {
+					return nil, errorf("invalid repeat %q", r)
+				}
+			}
+			if e.min < 0 || e.max < e.min {
+				return nil, errorf("invalid repeat %q", r)
+			}
+		}
+		m.el = append(m.el, e)
+	}
+	for _, e := range m.el[:len(m.el)-1] {
+		if e.end {
+			return nil, errorf("'>' in brackets before the last element")
+		}
+	}
+	return m, nil
+}
+
+// matchAt returns the end of a match of elements e and following at
+// position p of s, or -1 if there is no match.
+//
+// Results depend only on e and p and are memoized in memo, indexed by
+// e*(len(s)+1)+p, as the result + 2, with 0 for not yet computed.  The
+// memo is shared by all start positions, so that each (e, p) is computed
+// once for a sequence.
+func (m *PrositePattern) matchAt(s AA20, e, p int, memo []int) int {
+	if e == len(m.el) {
+		if m.cterm && p != len(s) {
+			return -1
+		}
+		return p
+	}
+	x := e*(len(s)+1) + p
+	if r := memo[x]; r != 0 {
+		return r - 2
+	}
+	r := -1
+	el := &m.el[e]
+	if el.end && p == len(s) {
+		r = p
+	} else {
+		n := 0
+		for n < el.max && p+n < len(s) && el.has(s[p+n]) {
+			n++
+		}
+		for c := n; c >= el.min; c-- {
+			if r = m.matchAt(s, e+1, p+c, memo); r >= 0 {
+				break
+			}
+		}
+	}
+	memo[x] = r + 2
+	return r
+}
+
+// AllIndex returns all matches of the pattern in s.
+//
+// A match is found for each start position where the pattern matches.
+// Matches may overlap.  Where elements with variable repeat counts allow
+// several matches from a start position, longer repeats of earlier
+// elements are preferred.  Results are in order of start position.
+//
+// Time is O(len(s) * number of elements * repeat range) for the whole
+// sequence.
+func (m *PrositePattern) AllIndex(s AA20) (x []Interval) {
+	last := len(s)
+	if m.nterm {
+		last = 0
+	}
+	memo := make([]int, len(m.el)*(len(s)+1))
+	for i := 0; i <= last && i < len(s); i++ {
+		if r := m.matchAt(s, 0, i, memo); r >= 0 {
+			x = append(x, Interval{i, r})
+		}
+	}
+	return
+}
+
+// PrositeEntry is an entry of the PROSITE database.
+//
+// Pattern is the compiled PA pattern of PATTERN entries.  Profile is the
+// compiled generalized profile, from the MA lines, of MATRIX entries.
+// Both are nil for other entries, such as rules, for which only the
+// identifying fields are read.
+type PrositeEntry struct {
+	ID          string // name, from the ID line
+	Type        string // PATTERN, MATRIX, or RULE
+	Accession   string // PSnnnnn
+	Description string
+	Pattern     *PrositePattern
+	Profile     *PrositeProfile
+}
+
+// AllIndex returns matches of the pattern or profile of the entry in s.
+//
+// For pattern entries, results are those of PrositePattern.AllIndex.  For
+// profile entries, results are the intervals of PrositeProfile.AllMatch.
+// Entries with neither return no matches.
+func (e *PrositeEntry) AllIndex(s AA20) (x []Interval) {
+	switch {
+	case e.Pattern != nil:
+		return e.Pattern.AllIndex(s)
+	case e.Profile != nil:
+		for _, m := range e.Profile.AllMatch(s) {
+			x = append(x, Interval{m.Start, m.End})
+		}
+	}
+	return
+}
+
+// PrositeReader reads entries from a PROSITE dat file.
+type PrositeReader struct {
+	r    *bufio.Reader
+	line int // number of lines read
+}
+
+// NewPrositeReader constructs a PrositeReader around an io.Reader.
+func NewPrositeReader(r io.Reader) PrositeReader {
+	return PrositeReader{r: bufio.NewReader(r)}
+}
+
+func (r *PrositeReader) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("PROSITE line %d: "+format,
+		append([]interface{}{r.line}, a...)...)
+}
+
+// ReadEntry returns a single entry on each call.
+//
+// Text before the ID line of an entry is ignored.
+// A successful read is indicated by err = nil for all entries, including
+// the last.  Subsequent calls return err = io.EOF.
+func (r *PrositeReader) ReadEntry() (*PrositeEntry, error) {
+	var e *PrositeEntry
+	var pa string
+	var ma []string
+	for {
+		b, err := readLine(r.r)
+		if err == io.EOF {
+			if e != nil {
+				err = r.errorf("unexpected EOF, no //")
+			}
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		r.line++
+		line := strings.TrimRight(string(b), " \t\r")
+		if len(line) < 2 {
+			continue
+		}
+		code, val := line[:2], ""
+		if len(line) > 5 {
+			val = line[5:]
+		}
+		if e == nil {
+			if code != "ID" {
+				continue
+			}
+			f := strings.Split(strings.TrimSuffix(val, "."), ";")
+			if len(f) != 2 {
+				return nil, r.errorf("invalid ID line")
+			}
+			e = &PrositeEntry{
+				ID:   strings.TrimSpace(f[0]),
+				Type: strings.TrimSpace(f[1]),
+			}
+			continue
+		}
+		switch code {
+		case "AC":
+			e.Accession = strings.TrimSuffix(val, ";")
+		case "DE":
+			if e.Description != "" {
+				e.Description += " "
+			}
+			e.Description += val
+		case "PA":
+			pa += val
+		case "MA":
+			ma = append(ma, val)
+		case "//":
+			if pa != "" {
+				if e.Pattern, err = ParseProsite(pa); err != nil {
+					return nil, r.errorf("%v", err)
+				}
+			}
+			if ma != nil {
+				if e.Profile, err = ParsePrositeProfile(ma); err != nil {
+					return nil, r.errorf("%v", err)
+				}
+			}
+			return e, nil
+		}
+	}
+}
+
+// ReadProsite reads all entries from a PROSITE dat file.
+func ReadProsite(r io.Reader) (p []*PrositeEntry, err error) {
+	pr := NewPrositeReader(r)
+	for {
+		e, err := pr.ReadEntry()
+		switch {
+		case err == io.EOF:
+			return p, nil
+		case err != nil:
+			return nil, err
+		}
+		p = append(p, e)
+	}
+}
@@ -0,0 +1,506 @@
+package bio
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PrositeProfile is a compiled PROSITE generalized profile, from the MA
+// lines of a MATRIX entry.
+//
+// A profile is a sequence of match positions, each with a score for each
+// residue of the alphabet and a deletion score, separated by insert
+// positions, each with a score for each inserted residue, scores for
+// beginning and ending an alignment at the position, and transition
+// scores between the match, insert, and delete states.
+//
+// Reference: Bucher et al. 1996, "A flexible motif search technique based
+// on generalized profiles", Comput. Chem. 20:3.
+type PrositeProfile struct {
+	Alphabet string  // residues with positional scores
+	CutOff   int     // raw score cut-off, of level 0
+	NCutOff  float64 // normalized score cut-off, of level 0, or NaN
+	R1, R2   float64 // linear normalization, NScore = R1 + R2*Score
+	linear   bool    // R1, R2 given
+	ix       [256]int
+	ins      []profileInsert // insert positions 0..L
+	mat      []profileMatch  // match positions 1..L, at 0..L-1
+}
+
+// profile alignment states, indexes of profileInsert.t
+const (
+	profB = iota // begin, as the source of a transition
+	profM
+	profI
+	profD
+	profE = profB // end, as the destination of a transition
+)
+
+// profileInsert holds the parameters of an insert position.
+type profileInsert struct {
+	i      []int     // insertion scores by alphabet index, unknown last
+	b0, b1 int       // begin scores, at the sequence start and elsewhere
+	e0, e1 int       // end scores, at the sequence end and elsewhere
+	t      [4][4]int // transition scores, [from][to]
+}
+
+// profileMatch holds the parameters of a match position.
+type profileMatch struct {
+	m []int // match scores by alphabet index, unknown last
+	d int   // deletion score
+}
+
+// profLow is the score "*", minus infinity.  It is small enough that sums
+// of a few do not overflow.
+const profLow = -1 << 28
+
+// ProfileMatch is an alignment of a PrositeProfile to a sequence.
+type ProfileMatch struct {
+	Start, End int     // residues aligned to the profile
+	Score      int     // raw score
+	NScore     float64 // normalized score, or NaN if not defined
+}
+
+// ParsePrositeProfile parses the MA lines of a PROSITE MATRIX entry.
+//
+// Argument ma holds the lines without the "MA   " line code.  Blocks
+// GENERAL_SPEC, DEFAULT, M, and I define the profile.  The level 0
+// CUT_OFF and a LINEAR NORMALIZATION are recorded.  Other blocks, such as
+// DISJOINT, are ignored.
+//
+// Parameters not given default to 0, except that B0 and E0 default to the
+// values of B1 and E1.  M0 and I0 give scores of residues not in the
+// alphabet.  A DEFAULT block sets defaults for the positions that follow
+// it.  Insert positions not given explicitly between match positions
+// take default values.
+func ParsePrositeProfile(ma []string) (*PrositeProfile, error) {
+	errorf := func(format string, a ...interface{}) error {
+		return fmt.Errorf("PROSITE profile: "+format, a...)
+	}
+	// collect blocks
+	type block struct {
+		name string
+		text string
+	}
+	var bl []block
+	for _, l := range ma {
+		l = strings.TrimSpace(l)
+		if strings.HasPrefix(l, "/") {
+			c := strings.IndexByte(l, ':')
+			if c < 0 {
+				return nil, errorf("invalid block %q", l)
+			}
+			bl = append(bl, block{name: l[1:c]})
+			l = l[c+1:]
+		} else if len(bl) == 0 {
+			return nil, errorf("text before first block")
+		}
+		bl[len(bl)-1].text += " " + l
+	}
+	p := &PrositeProfile{NCutOff: math.NaN(), R1: math.NaN(), R2: math.NaN()}
+	length := -1
+	defaults := map[string]string{}
+	lastIns := false // last position was an insert position
+	cutOff := false
+	for _, b := range bl {
+		kv, err := profileParams(b.text)
+		if err != nil {
+			return nil, errorf("%s: %v", b.name, err)
+		}
+		switch b.name {
+		case "GENERAL_SPEC":
+			p.Alphabet = kv["ALPHABET"]
+			if p.Alphabet == "" {
+				return nil, errorf("no alphabet")
+			}
+			for i := range p.ix {
+				p.ix[i] = len(p.Alphabet)
+			}
+			for i := 0; i < len(p.Alphabet); i++ {
+				c := p.Alphabet[i]
+				p.ix[c] = i
+				p.ix[c|LCBit] = i
+			}
+			if l, ok := kv["LENGTH"]; ok {
+				if length, err = strconv.Atoi(l); err != nil {
+					return nil, errorf("invalid length %q", l)
+				}
+			}
+			continue
+		case "NORMALIZATION":
+			if p.linear || kv["FUNCTION"] != "LINEAR" {
+				continue
+			}
+			r1, err1 := strconv.ParseFloat(kv["R1"], 64)
+			r2, err2 := strconv.ParseFloat(kv["R2"], 64)
+			if err1 != nil || err2 != nil {
+				return nil, errorf("invalid normalization")
+			}
+			p.R1, p.R2, p.linear = r1, r2, true
+			continue
+		case "CUT_OFF":
+			if l, ok := kv["LEVEL"]; ok && l != "0" {
+				continue
+			}
+			if p.CutOff, err = strconv.Atoi(kv["SCORE"]); err != nil {
+				return nil, errorf("invalid cut-off score %q", kv["SCORE"])
+			}
+			if ns, ok := kv["N_SCORE"]; ok {
+				ns = strings.Split(ns, ",")[0]
+				if p.NCutOff, err = strconv.ParseFloat(ns, 64); err != nil {
+					return nil, errorf("invalid cut-off n-score %q", ns)
+				}
+			}
+			cutOff = true
+			continue
+		case "DEFAULT", "M", "I":
+		default:
+			continue
+		}
+		if p.Alphabet == "" {
+			return nil, errorf("%s before GENERAL_SPEC", b.name)
+		}
+		if b.name == "DEFAULT" {
+			for k, v := range kv {
+				defaults[k] = v
+			}
+			continue
+		}
+		// merge parameters of the position with defaults
+		pv := map[string]string{}
+		for k, v := range defaults {
+			pv[k] = v
+		}
+		for k, v := range kv {
+			pv[k] = v
+		}
+		if b.name == "I" && lastIns {
+			return nil, errorf("consecutive insert positions")
+		}
+		if b.name == "M" {
+			if !lastIns {
+				// implied insert position
+				dv := map[string]string{}
+				for k, v := range defaults {
+					dv[k] = v
+				}
+				if err := p.addInsert(dv); err != nil {
+					return nil, errorf("%v", err)
+				}
+			}
+			if err := p.addMatch(pv); err != nil {
+				return nil, errorf("M: %v", err)
+			}
+			lastIns = false
+			continue
+		}
+		if err := p.addInsert(pv); err != nil {
+			return nil, errorf("I: %v", err)
+		}
+		lastIns = true
+	}
+	if p.Alphabet == "" {
+		return nil, errorf("no GENERAL_SPEC")
+	}
+	if len(p.mat) == 0 {
+		return nil, errorf("no match positions")
+	}
+	if !lastIns {
+		if err := p.addInsert(defaults); err != nil {
+			return nil, errorf("%v", err)
+		}
+	}
+	if length >= 0 && length != len(p.mat) {
+		return nil, errorf("length %d, %d match positions", length, len(p.mat))
+	}
+	if !cutOff {
+		return nil, errorf("no level 0 cut-off")
+	}
+	return p, nil
+}
+
+// profileParams parses the key=value; pairs of a block.
+func profileParams(text string) (map[string]string, error) {
+	kv := map[string]string{}
+	for text = strings.TrimSpace(text); text != ""; {
+		eq := strings.IndexByte(text, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("invalid parameter %q", text)
+		}
+		k := strings.TrimSpace(text[:eq])
+		text = strings.TrimSpace(text[eq+1:])
+		var v string
+		if strings.HasPrefix(text, "'") {
+			q := strings.IndexByte(text[1:], '\'')
+			if q < 0 {
+				return nil, fmt.Errorf("unclosed quote in %s", k)
+			}
+			v = text[1 : q+1]
+			text = text[q+2:]
+			if sc := strings.IndexByte(text, ';'); sc >= 0 {
+				text = text[sc+1:]
+			} else {
+				text = ""
+			}
+		} else {
+			sc := strings.IndexByte(text, ';')
+			if sc < 0 {
+				sc = len(text)
+				text += ";"
+			}
+			// lists may be continued across lines
+			v = strings.Join(strings.Fields(text[:sc]), "")
+			text = text[sc+1:]
+		}
+		kv[k] = v
+		text = strings.TrimSpace(text)
+	}
+	return kv, nil
+}
+
+// profileScore parses a score, with "*" for minus infinity.
+func profileScore(v string) (int, error) {
+	if v == "*" {
+		return profLow, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid score %q", v)
+	}
+	return n, nil
+}
+
+// scores parses a score vector of the alphabet from parameter k, with
+// the score of unknown residues from parameter k0.
+func (p *PrositeProfile) scores(pv map[string]string, k string) ([]int, error) {
+	s := make([]int, len(p.Alphabet)+1)
+	if v, ok := pv[k]; ok {
+		f := strings.Split(v, ",")
+		if len(f) != 1 && len(f) != len(p.Alphabet) {
+			return nil, fmt.Errorf("%s has %d scores, alphabet %d",
+				k, len(f), len(p.Alphabet))
+		}
+		for i := range s[:len(p.Alphabet)] {
+			var err error
+			if s[i], err = profileScore(f[i%len(f)]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if v, ok := pv[k+"0"]; ok {
+		var err error
+		if s[len(p.Alphabet)], err = profileScore(v); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (p *PrositeProfile) addMatch(pv map[string]string) (err error) {
+	var m profileMatch
+	if m.m, err = p.scores(pv, "M"); err != nil {
+		return
+	}
+	if v, ok := pv["D"]; ok {
+		if m.d, err = profileScore(v); err != nil {
+			return
+		}
+	}
+	p.mat = append(p.mat, m)
+	return
+}
+
+func (p *PrositeProfile) addInsert(pv map[string]string) (err error) {
+	var n profileInsert
+	if n.i, err = p.scores(pv, "I"); err != nil {
+		return
+	}
+	score := func(k, def string) int {
+		v, ok := pv[k]
+		if !ok {
+			if v, ok = pv[def]; !ok {
+				return 0
+			}
+		}
+		s, e := profileScore(v)
+		if e != nil && err == nil {
+			err = e
+		}
+		return s
+	}
+	n.b1 = score("B1", "")
+	n.b0 = score("B0", "B1")
+	n.e1 = score("E1", "")
+	n.e0 = score("E0", "E1")
+	const st = "BMID" // state letters, E in place of B as a destination
+	for f := range st {
+		for t := range st {
+			to := st[t]
+			if t == profE {
+				to = 'E'
+			}
+			n.t[f][t] = score(string([]byte{st[f], to}), "")
+		}
+	}
+	if err == nil {
+		p.ins = append(p.ins, n)
+	}
+	return
+}
+
+// Len returns the number of match positions of the profile.
+func (p *PrositeProfile) Len() int {
+	return len(p.mat)
+}
+
+// nscore returns the normalized score.
+func (p *PrositeProfile) nscore(score int) float64 {
+	if !p.linear {
+		return math.NaN()
+	}
+	return p.R1 + p.R2*float64(score)
+}
+
+// profCell is a score of an alignment state with the start of the
+// alignment.
+type profCell struct {
+	s, start int
+}
+
+func (c *profCell) max(s, start int) {
+	if s > c.s {
+		c.s, c.start = s, start
+	}
+}
+
+// best returns the best scoring alignment to residues lo:hi of s.
+//
+// Begin scores B0 apply at the start of s and end scores E0 at the end of
+// s.  Alignments must include at least one residue.
+func (p *PrositeProfile) best(s AA20, lo, hi int) (b ProfileMatch) {
+	L := len(p.mat)
+	low := profCell{profLow, -1}
+	m := make([]profCell, L+1) // m[0], d[0] unused
+	in := make([]profCell, L+1)
+	d := make([]profCell, L+1)
+	nm := make([]profCell, L+1)
+	ni := make([]profCell, L+1)
+	for k := range m {
+		m[k], in[k], d[k] = low, low, low
+	}
+	b.Score = profLow
+	clamp := func(c *profCell) {
+		if c.s < profLow {
+			*c = low
+		}
+	}
+	for i := lo; ; i++ {
+		// begin score at column i
+		begin := func(k int) int {
+			if i == 0 {
+				return p.ins[k].b0
+			}
+			return p.ins[k].b1
+		}
+		// deletions within column i
+		d[0] = low
+		for k := 0; k < L; k++ {
+			t := &p.ins[k].t
+			c := low
+			if k > 0 {
+				c.max(m[k].s+t[profM][profD], m[k].start)
+				c.max(d[k].s+t[profD][profD], d[k].start)
+			}
+			c.max(in[k].s+t[profI][profD], in[k].start)
+			c.max(begin(k)+t[profB][profD], i)
+			c.s += p.mat[k].d
+			clamp(&c)
+			d[k+1] = c
+		}
+		// end of alignment at column i
+		for k := 0; k <= L; k++ {
+			n := &p.ins[k]
+			e := n.e1
+			if i == len(s) {
+				e = n.e0
+			}
+			c := low
+			if k > 0 {
+				c.max(m[k].s+n.t[profM][profE], m[k].start)
+				c.max(d[k].s+n.t[profD][profE], d[k].start)
+			}
+			c.max(in[k].s+n.t[profI][profE], in[k].start)
+			if c.start >= 0 && c.start < i && c.s+e > b.Score {
+				b = ProfileMatch{Start: c.start, End: i, Score: c.s + e}
+			}
+		}
+		if i == hi {
+			break
+		}
+		// consume residue i
+		r := p.ix[s[i]]
+		for k := 0; k <= L; k++ {
+			n := &p.ins[k]
+			c := low
+			if k > 0 {
+				c.max(m[k].s+n.t[profM][profI], m[k].start)
+				c.max(d[k].s+n.t[profD][profI], d[k].start)
+			}
+			c.max(in[k].s+n.t[profI][profI], in[k].start)
+			c.max(begin(k)+n.t[profB][profI], i)
+			c.s += n.i[r]
+			clamp(&c)
+			ni[k] = c
+			if k == L {
+				break
+			}
+			c = low
+			if k > 0 {
+				c.max(m[k].s+n.t[profM][profM], m[k].start)
+				c.max(d[k].s+n.t[profD][profM], d[k].start)
+			}
+			c.max(in[k].s+n.t[profI][profM], in[k].start)
+			c.max(begin(k)+n.t[profB][profM], i)
+			c.s += p.mat[k].m[r]
+			clamp(&c)
+			nm[k+1] = c
+		}
+		nm[0] = low
+		m, nm = nm, m
+		in, ni = ni, in
+	}
+	b.NScore = p.nscore(b.Score)
+	return
+}
+
+// Best returns the best scoring alignment of the profile to s, regardless
+// of the cut-off.
+//
+// Time is O(len(s) * p.Len()).
+func (p *PrositeProfile) Best(s AA20) ProfileMatch {
+	return p.best(s, 0, len(s))
+}
+
+// AllMatch returns non-overlapping matches of the profile in s with
+// scores at least the cut-off.
+//
+// The best scoring alignment is found, then further matches are sought
+// in the residues before and after it.  Results are in order of position.
+func (p *PrositeProfile) AllMatch(s AA20) []ProfileMatch {
+	return p.allMatch(s, 0, len(s), nil)
+}
+
+func (p *PrositeProfile) allMatch(s AA20, lo, hi int, r []ProfileMatch) []ProfileMatch {
+	if hi <= lo {
+		return r
+	}
+	b := p.best(s, lo, hi)
+	if b.End <= b.Start || b.Score < p.CutOff {
+		return r
+	}
+	r = p.allMatch(s, lo, b.Start, r)
+	r = append(r, b)
+	return p.allMatch(s, b.End, hi, r)
+}
@@ -0,0 +1,131 @@
+package bio_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+// a small profile of C-W-C, with gaps and begin and end anywhere.
+var cwcProfile = []string{
+	"/GENERAL_SPEC: ALPHABET='ACGW'; LENGTH=3;",
+	"/NORMALIZATION: MODE=1; FUNCTION=LINEAR; R1=0.5; R2=0.1;",
+	"/CUT_OFF: LEVEL=0; SCORE=20; N_SCORE=2.5; MODE=1;",
+	"/CUT_OFF: LEVEL=-1; SCORE=10; N_SCORE=1.5; MODE=1;",
+	"/DEFAULT: D=-8; I=-4; B1=-100; E1=-100; MI=-6; MD=-6; IM=-6; DM=-6;",
+	"/I: B1=0; BI=*; BD=*;",
+	"/M: SY='C'; M=-2,10,-2,-5;",
+	"/M: SY='W'; M=-5,-5,-5,15;",
+	"/M: SY='C'; M=-2,10,-2,",
+	"    -5;",
+	"/I: E1=0; IE=*; DE=*;",
+}
+
+func ExampleParsePrositeProfile() {
+	p, err := bio.ParsePrositeProfile(cwcProfile)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(p.Alphabet, p.Len(), p.CutOff, p.NCutOff)
+	fmt.Printf("%+v\n", p.Best(bio.AA20("AACWCAA")))
+	fmt.Printf("%+v\n", p.Best(bio.AA20("ACGC")))
+	_, err = bio.ParsePrositeProfile(cwcProfile[:5])
+	fmt.Println(err)
+	// Output:
+	// ACGW 3 20 2.5
+	// {Start:2 End:5 Score:35 NScore:4}
+	// {Start:1 End:4 Score:15 NScore:2}
+	// PROSITE profile: no match positions
+}
+
+func ExamplePrositeProfile_AllMatch() {
+	p, _ := bio.ParsePrositeProfile(cwcProfile)
+	// an exact match and matches with substitutions
+	for _, m := range p.AllMatch(bio.AA20("CWCAAAGWCAAACWA")) {
+		fmt.Printf("%d %d %d %.1f\n", m.Start, m.End, m.Score, m.NScore)
+	}
+	// Output:
+	// 0 3 35 4.0
+	// 6 9 23 2.8
+	// 12 15 23 2.8
+}
+
+// With insertions and deletions disallowed, the best profile score should
+// be the best score of an ungapped window.
+func TestPrositeProfileUngapped(t *testing.T) {
+	const alpha = "ACDE"
+	for n := 0; n < 100; n++ {
+		L := 1 + rand.Intn(5)
+		ma := []string{
+			fmt.Sprintf("/GENERAL_SPEC: ALPHABET='%s'; LENGTH=%d;", alpha, L),
+			"/CUT_OFF: LEVEL=0; SCORE=0;",
+			"/DEFAULT: D=*; I=*; B1=*; E1=*;",
+			"/I: B1=0;",
+		}
+		m := make([][]int, L)
+		for k := range m {
+			m[k] = make([]int, len(alpha))
+			f := make([]string, len(alpha))
+			for a := range f {
+				m[k][a] = rand.Intn(21) - 10
+				f[a] = fmt.Sprint(m[k][a])
+			}
+			ma = append(ma, "/M: M="+strings.Join(f, ",")+";")
+		}
+		ma = append(ma, "/I: E1=0;")
+		p, err := bio.ParsePrositeProfile(ma)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := make(bio.AA20, L+rand.Intn(10))
+		for i := range s {
+			s[i] = alpha[rand.Intn(len(alpha))]
+		}
+		want := -1 << 31
+		for i := 0; i+L <= len(s); i++ {
+			w := 0
+			for k := range m {
+				w += m[k][strings.IndexByte(alpha, s[i+k])]
+			}
+			if w > want {
+				want = w
+			}
+		}
+		b := p.Best(s)
+		if b.Score != want || b.End-b.Start != L {
+			t.Fatalf("%s %v: best %+v, want score %d", s, ma, b, want)
+		}
+	}
+}
+
+// TestPrositeProfileGaps checks scores of alignments with insertions and
+// deletions, with substitutions penalized so that gaps score best.
+func TestPrositeProfileGaps(t *testing.T) {
+	ma := strings.Join(cwcProfile, "\n")
+	ma = strings.Replace(ma, "-2,10,-2,\n    -5", "-2,10,-2,-5", 1)
+	ma = strings.Replace(ma, "-2,10,-2,-5", "-50,10,-50,-50", -1)
+	ma = strings.Replace(ma, "-5,-5,-5,15", "-50,-50,-50,15", 1)
+	p, err := bio.ParsePrositeProfile(strings.Split(ma, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		s                 string
+		start, end, score int
+	}{
+		{"CWC", 0, 3, 35},
+		{"CWAC", 0, 4, 19},  // insertion, MI+I+IM
+		{"CWAAC", 0, 5, 15}, // II = 0
+		{"GGCCGG", 2, 4, 0}, // deletion, MD+D+DM
+	} {
+		b := p.Best(bio.AA20(tc.s))
+		if b.Start != tc.start || b.End != tc.end || b.Score != tc.score {
+			t.Errorf("%s: best %+v, want %d %d %d",
+				tc.s, b, tc.start, tc.end, tc.score)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package bio_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleParseProsite() {
+	m, err := bio.ParseProsite("C-x(2,4)-C-x(3)-[LIVMFYWC]-x(8)-H-x(3,5)-H.")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	s := bio.AA20("MSPKCPECGKSFSQSSNLQKHQRTHTGEKPYKC")
+	for _, x := range m.AllIndex(s) {
+		fmt.Println(x.Start, x.End, s[x.Start:x.End])
+	}
+	_, err = bio.ParseProsite("C-x(4,2)-C")
+	fmt.Println(err)
+	_, err = bio.ParseProsite("A-[G>]-C")
+	fmt.Println(err)
+	// Output:
+	// 4 25 CPECGKSFSQSSNLQKHQRTH
+	// PROSITE pattern "C-x(4,2)-C": invalid repeat "(4,2)"
+	// PROSITE pattern "A-[G>]-C": '>' in brackets before the last element
+}
+
+func ExamplePrositePattern_AllIndex() {
+	// N-glycosylation site.  Matches overlap.
+	m, _ := bio.ParseProsite("N-{P}-[ST]-{P}.")
+	fmt.Println(m.AllIndex(bio.AA20("MNNSTSANPSGNKTA")))
+	// anchors
+	m, _ = bio.ParseProsite("<M-x-K.")
+	fmt.Println(m.AllIndex(bio.AA20("MAKMAK")))
+	m, _ = bio.ParseProsite("K-[DE]-x-[GA>]")
+	fmt.Println(m.AllIndex(bio.AA20("KDLAKEL")))
+	// Output:
+	// [{1 5} {2 6} {11 15}]
+	// [{0 3}]
+	// [{0 4} {4 7}]
+}
+
+const prositeDat = `CC   PROSITE test data
+//
+ID   ASN_GLYCOSYLATION; PATTERN.
+AC   PS00001;
+DT   01-APR-1990 CREATED; 01-APR-1990 DATA UPDATE; 01-APR-1990 INFO UPDATE.
+DE   N-glycosylation site.
+PA   N-{P}-[ST]-{P}.
+//
+ID   ZINC_FINGER_C2H2_2; MATRIX.
+AC   PS50157;
+DE   Zinc finger C2H2 type domain profile.
+MA   /GENERAL_SPEC: ALPHABET='ACGW'; LENGTH=3;
+MA   /DISJOINT: DEFINITION=PROTECT; N1=2; N2=2;
+MA   /NORMALIZATION: MODE=1; FUNCTION=LINEAR; R1=0.5; R2=0.1;
+MA   /CUT_OFF: LEVEL=0; SCORE=20; N_SCORE=2.5; MODE=1;
+MA   /DEFAULT: D=-8; I=-4; B1=-100; E1=-100; MI=-6; MD=-6; IM=-6; DM=-6;
+MA   /I: B1=0; BI=*; BD=*;
+MA   /M: SY='C'; M=-2,10,-2,-5;
+MA   /M: SY='W'; M=-5,-5,-5,15;
+MA   /M: SY='C'; M=-2,10,-2,-5;
+MA   /I: E1=0; IE=*; DE=*;
+//
+ID   ZINC_FINGER_C2H2_1; PATTERN.
+AC   PS00028;
+DE   Zinc finger C2H2 type domain signature.
+PA   C-x(2,4)-C-x(3)-[LIVMFYWC]-x(8)-
+PA   H-x(3,5)-H.
+//
+`
+
+func ExampleReadProsite() {
+	p, err := bio.ReadProsite(strings.NewReader(prositeDat))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, e := range p {
+		fmt.Println(e.Accession, e.ID, e.Type, e.Description)
+		if e.Pattern != nil {
+			fmt.Println("   ", e.Pattern.Pattern)
+		}
+		if e.Profile != nil {
+			fmt.Println("    profile length", e.Profile.Len())
+		}
+	}
+	s := bio.AA20("CWCAAANWSGCAAACPECGKSFSQSSNLQKHQRTH")
+	for _, e := range p {
+		fmt.Println(e.ID, e.AllIndex(s))
+	}
+	// Output:
+	// PS00001 ASN_GLYCOSYLATION PATTERN N-glycosylation site.
+	//     N-{P}-[ST]-{P}.
+	// PS50157 ZINC_FINGER_C2H2_2 MATRIX Zinc finger C2H2 type domain profile.
+	//     profile length 3
+	// PS00028 ZINC_FINGER_C2H2_1 PATTERN Zinc finger C2H2 type domain signature.
+	//     C-x(2,4)-C-x(3)-[LIVMFYWC]-x(8)-H-x(3,5)-H.
+	// ASN_GLYCOSYLATION [{6 10}]
+	// ZINC_FINGER_C2H2_2 [{0 3}]
+	// ZINC_FINGER_C2H2_1 [{14 35}]
+}
+
+// TestPrositeRepeats checks a pattern with many variable repeats, which
+// is slow without memoization.
+func TestPrositeRepeats(t *testing.T) {
+	m, err := bio.ParseProsite("x(0,20)-x(0,20)-x(0,20)-x(0,20)-x(0,20)-W")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := bio.AA20(strings.Repeat("A", 200))
+	if x := m.AllIndex(s); len(x) != 0 {
+		t.Fatal(x)
+	}
+	s[150] = 'W'
+	x := m.AllIndex(s)
+	if len(x) != 101 || x[0] != (bio.Interval{50, 151}) ||
+		x[100] != (bio.Interval{150, 151}) {
+		t.Fatal(len(x), x[0], x[len(x)-1])
+	}
+}
@@ -0,0 +1,196 @@
+package bio
+
+// sa.go
+//
+// Suffix array construction.
+
+// SuffixArray constructs the suffix array of s.
+//
+// The result holds the starting positions of the non-empty suffixes of s
+// in lexicographic order, comparing byte-wise.  A shorter suffix that is
+// a prefix of a longer one sorts first.
+//
+// The algorithm is SA-IS, which runs in linear time.  Reference: Nong,
+// Zhang, and Chan 2009, "Linear suffix array construction by almost pure
+// induced-sorting", DCC 2009.
+func SuffixArray(s []byte) []int {
+	x := make([]int, len(s)+1)
+	for i, b := range s {
+		x[i] = int(b) + 1
+	}
+	// x ends with the unique smallest symbol 0, so the suffix at len(s)
+	// sorts first.  Drop it.
+	return sais(x, 257)[1:]
+}
+
+// LCP computes the longest common prefix array of s, given its suffix
+// array sa.
+//
+// Element i of the result is the length of the longest common prefix of
+// the suffixes at sa[i-1] and sa[i].  Element 0 is 0.
+//
+// The algorithm is that of Kasai et al. 2001, "Linear-time longest-common-
+// prefix computation in suffix arrays and its applications", CPM 2001.
+func LCP(s []byte, sa []int) []int {
+	rank := make([]int, len(sa))
+	for i, p := range sa {
+		rank[p] = i
+	}
+	lcp := make([]int, len(sa))
+	h := 0
+	for p, r := range rank {
+		if r == 0 {
+			h = 0
+			continue
+		}
+		q := sa[r-1]
+		for p+h < len(s) && q+h < len(s) && s[p+h] == s[q+h] {
+			h++
+		}
+		lcp[r] = h
+		if h > 0 {
+			h--
+		}
+	}
+	return lcp
+}
+
+// sais computes the suffix array of s by SA-IS.
+//
+// Symbols of s must be in the range [0, k) and s must end with a unique
+// symbol smaller than all others.
+func sais(s []int, k int) []int {
+	n := len(s)
+	sa := make([]int, n)
+	if n == 1 {
+		return sa
+	}
+	// classify suffixes as S-type (true) or L-type (false)
+	t := make([]bool, n)
+	t[n-1] = true
+	for i := n - 2; i >= 0; i-- {
+		t[i] = s[i] < s[i+1] || s[i] == s[i+1] && t[i+1]
+	}
+	isLMS := func(i int) bool { return i > 0 && t[i] && !t[i-1] }
+
+	// bucket boundaries
+	bkt := make([]int, k)
+	for _, c := range s {
+		bkt[c]++
+	}
+	ptr := make([]int, k)
+	heads := func() {
+		sum := 0
+		for c, b := range bkt {
+			ptr[c] = sum
+			sum += b
+		}
+	}
+	tails := func() {
+		sum := 0
+		for c, b := range bkt {
+			sum += b
+			ptr[c] = sum
+		}
+	}
+	induce := func() {
+		heads()
+		for i := 0; i < n; i++ {
+			if j := sa[i] - 1; j >= 0 && !t[j] {
+				sa[ptr[s[j]]] = j
+				ptr[s[j]]++
+			}
+		}
+		tails()
+		for i := n - 1; i >= 0; i-- {
+			if j := sa[i] - 1; j >= 0 && t[j] {
+				ptr[s[j]]--
+				sa[ptr[s[j]]] = j
+			}
+		}
+	}
+
+	// sort LMS substrings
+	for i := range sa {
+		sa[i] = -1
+	}
+	tails()
+	for i := n - 1; i > 0; i-- {
+		if isLMS(i) {
+			ptr[s[i]]--
+			sa[ptr[s[i]]] = i
+		}
+	}
+	induce()
+
+	// compact sorted LMS substrings into the front of sa and name them
+	m := 0
+	for _, p := range sa {
+		if isLMS(p) {
+			sa[m] = p
+			m++
+		}
+	}
+	for i := m; i < n; i++ {
+		sa[i] = -1
+	}
+	name := 0
+	prev := -1
+	for i := 0; i < m; i++ {
+		p := sa[i]
+		diff := prev < 0
+		for d := 0; !diff; d++ {
+			if s[p+d] != s[prev+d] || t[p+d] != t[prev+d] {
+				diff = true
+			} else if d > 0 && isLMS(p+d) {
+				break
+			}
+		}
+		if diff {
+			name++
+			prev = p
+		}
+		// LMS positions are at least 2 apart, so p/2 is unique
+		sa[m+p/2] = name - 1
+	}
+	// gather names in text order, forming the reduced string s1
+	j := n
+	for i := n - 1; i >= m; i-- {
+		if sa[i] >= 0 {
+			j--
+			sa[j] = sa[i]
+		}
+	}
+	s1 := append([]int{}, sa[n-m:]...)
+
+	// suffix array of the reduced string
+	var sa1 []int
+	if name < m {
+		sa1 = sais(s1, name)
+	} else {
+		sa1 = make([]int, m)
+		for i, c := range s1 {
+			sa1[c] = i
+		}
+	}
+
+	// map back to LMS positions and induce the full suffix array
+	j = 0
+	for i := 1; i < n; i++ {
+		if isLMS(i) {
+			s1[j] = i
+			j++
+		}
+	}
+	for i := range sa {
+		sa[i] = -1
+	}
+	tails()
+	for i := m - 1; i >= 0; i-- {
+		p := s1[sa1[i]]
+		ptr[s[p]]--
+		sa[ptr[s[p]]] = p
+	}
+	induce()
+	return sa
+}
@@ -0,0 +1,55 @@
+package bio_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleSuffixArray() {
+	s := []byte("banana")
+	sa := bio.SuffixArray(s)
+	fmt.Println(sa)
+	fmt.Println(bio.LCP(s, sa))
+	// Output:
+	// [5 3 1 0 4 2]
+	// [0 1 3 0 0 2]
+}
+
+func TestSuffixArray(t *testing.T) {
+	for _, k := range []int{1, 2, 4, 256} {
+		for n := 0; n < 300; n += 7 {
+			s := make([]byte, n)
+			for i := range s {
+				s[i] = byte(rand.Intn(k))
+			}
+			ss := make([]string, n)
+			for i := range ss {
+				ss[i] = string(s[i:])
+			}
+			sort.Strings(ss)
+			sa := bio.SuffixArray(s)
+			lcp := bio.LCP(s, sa)
+			for i, p := range sa {
+				if n-len(ss[i]) != p {
+					t.Fatalf("k %d, n %d: sa[%d] = %d, want %d",
+						k, n, i, p, n-len(ss[i]))
+				}
+				if i == 0 {
+					continue
+				}
+				h := 0
+				for h < len(ss[i]) && h < len(ss[i-1]) && ss[i][h] == ss[i-1][h] {
+					h++
+				}
+				if lcp[i] != h {
+					t.Fatalf("k %d, n %d: lcp[%d] = %d, want %d",
+						k, n, i, lcp[i], h)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package bio
+
+import "sort"
+
+// SuffixAutomaton is the suffix automaton of a sequence, the minimal
+// deterministic automaton recognizing its substrings.
+//
+// Member S is the Seq passed to the constructor.  As with KMP, the
+// automaton will not work if S is subsequently modified.
+// Construction is byte-wise and so case sensitive.
+type SuffixAutomaton struct {
+	S  Seq
+	st []samState
+}
+
+type samState struct {
+	len   int          // length of the longest string of the state
+	link  int          // suffix link
+	next  map[byte]int // transitions
+	first int          // end position of the first occurrence, inclusive
+	count int          // number of occurrences
+	term  bool         // state recognizes a suffix of S
+}
+
+// Repeat is a repeated substring located by SuffixAutomaton methods.
+type Repeat struct {
+	Start int // start of the first occurrence
+	Len   int // length
+	Count int // number of occurrences, possibly overlapping
+}
+
+// NewSuffixAutomaton constructs the suffix automaton of s.
+//
+// Construction time is linear in the length of s.  The automaton has at
+// most 2*len(s) states.
+func NewSuffixAutomaton(s Seq) *SuffixAutomaton {
+	a := &SuffixAutomaton{S: s, st: make([]samState, 1, 2*len(s)+1)}
+	a.st[0] = samState{link: -1, next: map[byte]int{}}
+	last := 0
+	for i, c := range s {
+		cur := len(a.st)
+		a.st = append(a.st, samState{
+			len:   a.st[last].len + 1,
+			next:  map[byte]int{},
+			first: i,
+			count: 1,
+		})
+		p := last
+		for ; p >= 0; p = a.st[p].link {
+			if _, ok := a.st[p].next[c]; ok {
+				break
+			}
+			a.st[p].next[c] = cur
+		}
+		if p < 0 {
+			a.st[cur].link = 0
+		} else if q := a.st[p].next[c]; a.st[p].len+1 == a.st[q].len {
+			a.st[cur].link = q
+		} else {
+			clone := len(a.st)
+			cs := a.st[q]
+			cs.len = a.st[p].len + 1
+			cs.count = 0
+			cs.next = make(map[byte]int, len(a.st[q].next))
+			for b, x := range a.st[q].next {
+				cs.next[b] = x
+			}
+			a.st = append(a.st, cs)
+			for ; p >= 0 && a.st[p].next[c] == q; p = a.st[p].link {
+				a.st[p].next[c] = clone
+			}
+			a.st[q].link = clone
+			a.st[cur].link = clone
+		}
+		last = cur
+	}
+	for p := last; p >= 0; p = a.st[p].link {
+		a.st[p].term = true
+	}
+	// accumulate occurrence counts up suffix links, longest states first
+	for _, v := range a.byLen() {
+		if l := a.st[v].link; l >= 0 {
+			a.st[l].count += a.st[v].count
+		}
+	}
+	return a
+}
+
+// byLen returns states in order of decreasing len.
+func (a *SuffixAutomaton) byLen() []int {
+	n := len(a.S)
+	c := make([]int, n+2)
+	for _, s := range a.st {
+		c[n-s.len+1]++
+	}
+	for i := 1; i < len(c); i++ {
+		c[i] += c[i-1]
+	}
+	o := make([]int, len(a.st))
+	for v, s := range a.st {
+		x := n - s.len
+		o[c[x]] = v
+		c[x]++
+	}
+	return o
+}
+
+// sub returns the longest string of state v.
+func (a *SuffixAutomaton) sub(v int) Seq {
+	if v == 0 {
+		return Seq{}
+	}
+	s := a.st[v]
+	return a.S[s.first-s.len+1 : s.first+1]
+}
+
+// DistinctSubstrings returns the number of distinct non-empty substrings
+// of S.
+func (a *SuffixAutomaton) DistinctSubstrings() int {
+	n := 0
+	for _, s := range a.st[1:] {
+		n += s.len - a.st[s.link].len
+	}
+	return n
+}
+
+// LongestRepeat returns the longest substring occurring at least twice
+// in S, possibly overlapping.
+//
+// The first occurrence is returned.  The result is empty if S has no
+// repeated symbol.
+func (a *SuffixAutomaton) LongestRepeat() Seq {
+	best := 0
+	for v, s := range a.st {
+		b := a.st[best]
+		if s.count >= 2 && (s.len > b.len ||
+			s.len == b.len && s.first < b.first) {
+			best = v
+		}
+	}
+	return a.sub(best)
+}
+
+// MaximalRepeats returns the maximal repeats of S with at least min
+// occurrences.
+//
+// A maximal repeat occurs at least twice and cannot be extended to the left
+// or right without losing an occurrence.  Results are ordered by decreasing
+// length, then by start position.  Min values less than 2 are taken as 2.
+func (a *SuffixAutomaton) MaximalRepeats(min int) (r []Repeat) {
+	if min < 2 {
+		min = 2
+	}
+	// the longest string of every state is left-maximal.  it is right-
+	// maximal if it is followed by different symbols or ends S.
+	for _, s := range a.st[1:] {
+		if s.count >= min && (len(s.next) > 1 || s.term) {
+			r = append(r, Repeat{s.first - s.len + 1, s.len, s.count})
+		}
+	}
+	sort.Sort(repeatList(r))
+	return
+}
+
+// repeatList is a container type satisfying sort.Interface.
+type repeatList []Repeat
+
+func (l repeatList) Len() int      { return len(l) }
+func (l repeatList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l repeatList) Less(i, j int) bool {
+	if l[i].Len != l[j].Len {
+		return l[i].Len > l[j].Len
+	}
+	return l[i].Start < l[j].Start
+}
+
+// match calls fn for each position of t with the state and length of the
+// longest substring of S ending at that position of t.
+func (a *SuffixAutomaton) match(t Seq, fn func(i, v, l int)) {
+	v, l := 0, 0
+	for i, c := range t {
+		for v > 0 {
+			if _, ok := a.st[v].next[c]; ok {
+				break
+			}
+			v = a.st[v].link
+			l = a.st[v].len
+		}
+		if x, ok := a.st[v].next[c]; ok {
+			v = x
+			l++
+		} else {
+			l = 0
+		}
+		fn(i, v, l)
+	}
+}
+
+// ShortestAbsent returns the shortest substring of t that does not occur
+// in S.
+//
+// Where there are several, the one ending first in t is returned.  The
+// result is nil if all substrings of t occur in S.
+func (a *SuffixAutomaton) ShortestAbsent(t Seq) Seq {
+	var r Seq
+	a.match(t, func(i, v, l int) {
+		// substrings ending at i longer than l are absent
+		if l < i+1 && (r == nil || l+1 < len(r)) {
+			r = t[i-l : i+1]
+		}
+	})
+	return r
+}
+
+// LongestCommonSubstring returns the longest substring common to all
+// sequences of s.
+//
+// Where there are several, the one occurring first in s[0] is returned.
+func LongestCommonSubstring(s []Seq) Seq {
+	if len(s) == 0 {
+		return nil
+	}
+	a := NewSuffixAutomaton(s[0])
+	// best[v] is the longest length of state v matched in all sequences
+	best := make([]int, len(a.st))
+	for v, st := range a.st {
+		best[v] = st.len
+	}
+	order := a.byLen()
+	cur := make([]int, len(a.st))
+	for _, t := range s[1:] {
+		for v := range cur {
+			cur[v] = 0
+		}
+		a.match(t, func(i, v, l int) {
+			if l > cur[v] {
+				cur[v] = l
+			}
+		})
+		// a match at a state implies full matches at its suffix links
+		for _, v := range order {
+			if l := a.st[v].link; l >= 0 && cur[v] > 0 {
+				cur[l] = a.st[l].len
+			}
+		}
+		for v, c := range cur {
+			if c < best[v] {
+				best[v] = c
+			}
+		}
+	}
+	bv, bl := 0, 0
+	for v, l := range best {
+		st := a.st[v]
+		if l > bl || l == bl && l > 0 && st.first-l < a.st[bv].first-bl {
+			bv, bl = v, l
+		}
+	}
+	if bl == 0 {
+		return Seq{}
+	}
+	st := a.st[bv]
+	return s[0][st.first-bl+1 : st.first+1]
+}
@@ -0,0 +1,135 @@
+package bio_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+func ExampleSuffixAutomaton() {
+	a := bio.NewSuffixAutomaton(bio.Seq("GATTACATTAC"))
+	fmt.Println(a.DistinctSubstrings())
+	fmt.Println(a.LongestRepeat())
+	fmt.Println(a.ShortestAbsent(bio.Seq("TACAG")))
+	// Output:
+	// 49
+	// ATTAC
+	// AG
+}
+
+func ExampleSuffixAutomaton_MaximalRepeats() {
+	s := bio.Seq("GATTACATTACAGATT")
+	for _, r := range bio.NewSuffixAutomaton(s).MaximalRepeats(2) {
+		fmt.Println(s[r.Start:r.Start+r.Len], r.Start, r.Count)
+	}
+	// Output:
+	// ATTACA 1 2
+	// GATT 0 2
+	// ATT 1 3
+	// A 1 6
+	// T 2 6
+}
+
+func ExampleLongestCommonSubstring() {
+	fmt.Println(bio.LongestCommonSubstring([]bio.Seq{
+		bio.Seq("GATTACA"),
+		bio.Seq("TAGACCA"),
+		bio.Seq("ATACA"),
+	}))
+	// Output:
+	// TA
+}
+
+func TestSuffixAutomaton(t *testing.T) {
+	rs := func(n int) bio.Seq {
+		s := make(bio.Seq, n)
+		for i := range s {
+			s[i] = "ACG"[rand.Intn(3)]
+		}
+		return s
+	}
+	for trial := 0; trial < 20; trial++ {
+		s := rs(1 + rand.Intn(40))
+		u := rs(1 + rand.Intn(40))
+		a := bio.NewSuffixAutomaton(s)
+		sub := map[string]int{}
+		for i := range s {
+			for j := i + 1; j <= len(s); j++ {
+				sub[string(s[i:j])]++
+			}
+		}
+		if got := a.DistinctSubstrings(); got != len(sub) {
+			t.Fatalf("%s: DistinctSubstrings %d, want %d", s, got, len(sub))
+		}
+		lr := 0
+		for k, n := range sub {
+			if n >= 2 && len(k) > lr {
+				lr = len(k)
+			}
+		}
+		if got := a.LongestRepeat(); len(got) != lr || lr > 0 && sub[string(got)] < 2 {
+			t.Fatalf("%s: LongestRepeat %s, want length %d", s, got, lr)
+		}
+		sa := 0
+	absent:
+		for l := 1; l <= len(u); l++ {
+			for i := 0; i+l <= len(u); i++ {
+				if sub[string(u[i:i+l])] == 0 {
+					sa = l
+					break absent
+				}
+			}
+		}
+		if got := a.ShortestAbsent(u); len(got) != sa || sa > 0 && sub[string(got)] > 0 {
+			t.Fatalf("%s %s: ShortestAbsent %s, want length %d", s, u, got, sa)
+		}
+		lcs := 0
+		for i := range u {
+			for j := i + 1; j <= len(u); j++ {
+				if sub[string(u[i:j])] > 0 && j-i > lcs {
+					lcs = j - i
+				}
+			}
+		}
+		if got := bio.LongestCommonSubstring([]bio.Seq{s, u}); len(got) != lcs {
+			t.Fatalf("%s %s: LongestCommonSubstring %s, want length %d",
+				s, u, got, lcs)
+		}
+		for _, r := range a.MaximalRepeats(2) {
+			k := string(s[r.Start : r.Start+r.Len])
+			if sub[k] != r.Count {
+				t.Fatalf("%s: repeat %s count %d, want %d", s, k, r.Count, sub[k])
+			}
+			for _, b := range []byte("ACG") {
+				if sub[string(b)+k] == r.Count || sub[k+string(b)] == r.Count {
+					t.Fatalf("%s: repeat %s not maximal", s, k)
+				}
+			}
+		}
+	}
+	// empty sequences
+	e := bio.NewSuffixAutomaton(bio.Seq(""))
+	if n := e.DistinctSubstrings(); n != 0 {
+		t.Fatal("empty: DistinctSubstrings", n)
+	}
+	if r := e.LongestRepeat(); len(r) != 0 {
+		t.Fatalf("empty: LongestRepeat %s", r)
+	}
+	if r := e.MaximalRepeats(2); len(r) != 0 {
+		t.Fatal("empty: MaximalRepeats", r)
+	}
+	if r := e.ShortestAbsent(bio.Seq("CA")); string(r) != "C" {
+		t.Fatalf("empty: ShortestAbsent %s", r)
+	}
+	for _, s := range [][]bio.Seq{
+		{bio.Seq(""), bio.Seq("ACG")},
+		{bio.Seq("ACG"), bio.Seq("")},
+		{bio.Seq("AC"), bio.Seq("GT")},
+	} {
+		if r := bio.LongestCommonSubstring(s); len(r) != 0 {
+			t.Fatalf("%q: LongestCommonSubstring %s", s, r)
+		}
+	}
+}
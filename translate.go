@@ -89,6 +89,8 @@ const (
 // IsStartCodon tests for a DNA or RNA start codon.
 //
 // The arguments should be a sequence of DNA8 or RNA8 symbols.
+// Only AUG is recognized.  See GeneticCode.IsStartCodon for alternative
+// start codons.
 func IsStartCodon(b0, b1, b2 byte) bool {
 	return b0&6 == 'a'&6 && b1&6 == 'u'&6 && b2&6 == 'g'&6
 }
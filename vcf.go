@@ -0,0 +1,365 @@
+package bio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VCFHeader holds the header of a VCF file.
+type VCFHeader struct {
+	Meta    []string // meta-information lines, without the leading ##
+	Samples []string // sample names from the column header line
+}
+
+// FileFormat returns the value of the fileformat meta-information line,
+// for example "VCFv4.2", or an empty string if there is none.
+func (h VCFHeader) FileFormat() string {
+	for _, m := range h.Meta {
+		if strings.HasPrefix(m, "fileformat=") {
+			return m[len("fileformat="):]
+		}
+	}
+	return ""
+}
+
+// VCFRecord is a single data line of a VCF file.
+//
+// Pos is converted from the 1-based coordinate of VCF to a 0-based index.
+// Missing values, written "." in VCF, are represented as nil slices,
+// empty strings, or for Qual, NaN.
+type VCFRecord struct {
+	Chrom   string
+	Pos     int
+	ID      []string
+	Ref     string
+	Alt     []string
+	Qual    float64
+	Filter  []string
+	Info    []VCFInfo
+	Format  []string   // keys of the sample columns
+	Samples [][]string // for each sample, a value for each Format key
+}
+
+// VCFInfo is a key-value pair of the INFO column.  Flags have an empty
+// Value.
+type VCFInfo struct {
+	Key, Value string
+}
+
+// InfoValue returns the value of an INFO key.
+//
+// Result ok is false if the record has no such key.
+func (r VCFRecord) InfoValue(key string) (value string, ok bool) {
+	for _, i := range r.Info {
+		if i.Key == key {
+			return i.Value, true
+		}
+	}
+	return "", false
+}
+
+// SampleValue returns the value of a Format key for sample number s.
+//
+// Result ok is false if the key is not present for the sample.
+func (r VCFRecord) SampleValue(s int, key string) (value string, ok bool) {
+	if s < 0 || s >= len(r.Samples) {
+		return "", false
+	}
+	for i, k := range r.Format {
+		if k == key {
+			if i < len(r.Samples[s]) {
+				return r.Samples[s][i], true
+			}
+			break
+		}
+	}
+	return "", false
+}
+
+// Genotype parses the GT value for sample number s.
+//
+// Alleles are returned as indexes where 0 is Ref and 1 is the first Alt.
+// A missing allele is returned as -1.  Phased is true if alleles are
+// separated by '|'.
+func (r VCFRecord) Genotype(s int) (alleles []int, phased bool, err error) {
+	gt, ok := r.SampleValue(s, "GT")
+	if !ok {
+		return nil, false, errors.New("VCF: no GT value")
+	}
+	phased = strings.IndexByte(gt, '|') >= 0
+	for _, a := range strings.FieldsFunc(gt, func(c rune) bool {
+		return c == '|' || c == '/'
+	}) {
+		if a == "." {
+			alleles = append(alleles, -1)
+			continue
+		}
+		n, err := strconv.Atoi(a)
+		if err != nil || n < 0 || n > len(r.Alt) {
+			return nil, false, fmt.Errorf("VCF: invalid genotype %s", gt)
+		}
+		alleles = append(alleles, n)
+	}
+	return
+}
+
+// VCFReader reads records from a VCF stream.
+type VCFReader struct {
+	r      *bufio.Reader
+	line   int // number of lines read
+	Header VCFHeader
+}
+
+// NewVCFReader constructs a VCFReader around an io.Reader.
+//
+// The header is read immediately and is available in the Header field.
+func NewVCFReader(r io.Reader) (*VCFReader, error) {
+	v := &VCFReader{r: bufio.NewReader(r)}
+	for {
+		b, err := readLine(v.r)
+		if err == io.EOF {
+			return nil, v.errorf("no header line")
+		}
+		if err != nil {
+			return nil, err
+		}
+		v.line++
+		line := string(b)
+		if strings.HasPrefix(line, "##") {
+			v.Header.Meta = append(v.Header.Meta, line[2:])
+			continue
+		}
+		if !strings.HasPrefix(line, "#CHROM") {
+			return nil, v.errorf("expected #CHROM header line")
+		}
+		c := strings.Split(line, "\t")
+		if len(c) < 8 || len(c) == 9 {
+			return nil, v.errorf("%d columns in header line", len(c))
+		}
+		if len(c) > 9 {
+			v.Header.Samples = c[9:]
+		}
+		return v, nil
+	}
+}
+
+func (r *VCFReader) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("VCF line %d: "+format,
+		append([]interface{}{r.line}, a...)...)
+}
+
+// vcfList splits a column into a list, with "." giving nil.
+func vcfList(s, sep string) []string {
+	if s == "." || s == "" {
+		return nil
+	}
+	return strings.Split(s, sep)
+}
+
+// ReadRecord returns a single record on each call.
+//
+// A successful read is indicated by err = nil for all records, including
+// the last.  Subsequent calls return err = io.EOF.
+func (r *VCFReader) ReadRecord() (v VCFRecord, err error) {
+	var b []byte
+	for len(b) == 0 {
+		if b, err = readLine(r.r); err != nil {
+			return
+		}
+		r.line++
+	}
+	c := strings.Split(string(b), "\t")
+	// with samples, FORMAT and all sample columns are required
+	if n := len(r.Header.Samples); n > 0 && len(c) != 9+n ||
+		n == 0 && len(c) != 8 && len(c) != 9 {
+		return v, r.errorf("%d columns", len(c))
+	}
+	v.Chrom = c[0]
+	if v.Pos, err = strconv.Atoi(c[1]); err != nil {
+		return v, r.errorf("invalid position %s", c[1])
+	}
+	v.Pos--
+	v.ID = vcfList(c[2], ";")
+	v.Ref = c[3]
+	v.Alt = vcfList(c[4], ",")
+	v.Qual = math.NaN()
+	if c[5] != "." {
+		if v.Qual, err = strconv.ParseFloat(c[5], 64); err != nil {
+			return v, r.errorf("invalid quality %s", c[5])
+		}
+	}
+	v.Filter = vcfList(c[6], ";")
+	for _, i := range vcfList(c[7], ";") {
+		kv := VCFInfo{Key: i}
+		if eq := strings.IndexByte(i, '='); eq >= 0 {
+			kv = VCFInfo{i[:eq], i[eq+1:]}
+		}
+		v.Info = append(v.Info, kv)
+	}
+	if len(c) > 8 {
+		v.Format = strings.Split(c[8], ":")
+		for _, s := range c[9:] {
+			v.Samples = append(v.Samples, strings.Split(s, ":"))
+		}
+	}
+	return v, nil
+}
+
+// ReadVCF reads a VCF stream, returning the header and all records.
+func ReadVCF(r io.Reader) (h VCFHeader, v []VCFRecord, err error) {
+	vr, err := NewVCFReader(r)
+	if err != nil {
+		return
+	}
+	for {
+		rec, err := vr.ReadRecord()
+		switch {
+		case err == io.EOF:
+			return vr.Header, v, nil
+		case err != nil:
+			return vr.Header, nil, err
+		}
+		v = append(v, rec)
+	}
+}
+
+// VCFWriter writes VCF.
+//
+// Output is buffered.  Call Flush after the last record is written.
+type VCFWriter struct {
+	w *bufio.Writer
+}
+
+// NewVCFWriter constructs a VCFWriter around an io.Writer and writes
+// the header h.
+//
+// If h has no fileformat meta-information line, one for VCFv4.2 is
+// written first.
+func NewVCFWriter(w io.Writer, h VCFHeader) *VCFWriter {
+	vw := &VCFWriter{bufio.NewWriter(w)}
+	if h.FileFormat() == "" {
+		vw.w.WriteString("##fileformat=VCFv4.2\n")
+	}
+	for _, m := range h.Meta {
+		vw.w.WriteString("##" + m + "\n")
+	}
+	vw.w.WriteString("#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO")
+	if len(h.Samples) > 0 {
+		vw.w.WriteString("\tFORMAT\t" + strings.Join(h.Samples, "\t"))
+	}
+	vw.w.WriteByte('\n')
+	return vw
+}
+
+// vcfJoin joins a list into a column, with an empty list giving ".".
+func vcfJoin(l []string, sep string) string {
+	if len(l) == 0 {
+		return "."
+	}
+	return strings.Join(l, sep)
+}
+
+// WriteRecord writes a single record.
+func (w *VCFWriter) WriteRecord(v VCFRecord) error {
+	qual := "."
+	if !math.IsNaN(v.Qual) {
+		qual = strconv.FormatFloat(v.Qual, 'g', -1, 64)
+	}
+	info := make([]string, len(v.Info))
+	for i, kv := range v.Info {
+		info[i] = kv.Key
+		if kv.Value != "" {
+			info[i] += "=" + kv.Value
+		}
+	}
+	fmt.Fprintf(w.w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s",
+		v.Chrom, v.Pos+1, vcfJoin(v.ID, ";"), v.Ref, vcfJoin(v.Alt, ","),
+		qual, vcfJoin(v.Filter, ";"), vcfJoin(info, ";"))
+	if len(v.Format) > 0 {
+		w.w.WriteString("\t" + strings.Join(v.Format, ":"))
+		for _, s := range v.Samples {
+			w.w.WriteString("\t" + strings.Join(s, ":"))
+		}
+	}
+	return w.w.WriteByte('\n')
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *VCFWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// CallSNPs compares a sequence to a reference of the same length and
+// returns a VCF record for each position where they differ.
+//
+// Positions are compared as by DNA8.Hamming.  Ref and Alt of the results
+// are upper case.  Argument chrom is used as Chrom of the results.
+// If the lengths differ, only positions up to the shorter length are
+// compared.
+func CallSNPs(chrom string, ref, s DNA8) []VCFRecord {
+	var v []VCFRecord
+	if len(s) < len(ref) {
+		ref = ref[:len(s)]
+	}
+	for i, b := range ref {
+		if b&6 != s[i]&6 {
+			v = append(v, VCFRecord{
+				Chrom: chrom,
+				Pos:   i,
+				Ref:   string(b &^ LCBit),
+				Alt:   []string{string(s[i] &^ LCBit)},
+				Qual:  math.NaN(),
+			})
+		}
+	}
+	return v
+}
+
+type vcfByPos []VCFRecord
+
+func (v vcfByPos) Len() int           { return len(v) }
+func (v vcfByPos) Less(i, j int) bool { return v[i].Pos < v[j].Pos }
+func (v vcfByPos) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+
+// ApplyVariants returns the sequence resulting from applying variants to
+// a reference sequence.
+//
+// The first Alt allele of each variant is applied.  SNVs, MNVs, insertions,
+// and deletions are supported, as are general substitutions where Ref and
+// Alt differ in length.  Variants may be in any order but must not overlap.
+// Ref of each variant must match the reference, ignoring case.  The Chrom
+// of variants is not checked.  Symbolic alleles such as <DEL> and
+// records with no Alt allele give an error.
+func ApplyVariants(ref DNA8, v []VCFRecord) (DNA8, error) {
+	v = append([]VCFRecord{}, v...)
+	sort.Stable(vcfByPos(v))
+	var r DNA8
+	p := 0 // position in ref copied up to
+	for _, x := range v {
+		if len(x.Alt) == 0 || strings.IndexAny(x.Alt[0], "<>[]*.") >= 0 {
+			return nil, fmt.Errorf("VCF %s:%d: unsupported Alt", x.Chrom, x.Pos+1)
+		}
+		end := x.Pos + len(x.Ref)
+		switch {
+		case x.Pos < p:
+			return nil, fmt.Errorf("VCF %s:%d: overlapping variants",
+				x.Chrom, x.Pos+1)
+		case end > len(ref):
+			return nil, fmt.Errorf("VCF %s:%d: beyond end of reference",
+				x.Chrom, x.Pos+1)
+		case !strings.EqualFold(string(ref[x.Pos:end]), x.Ref):
+			return nil, fmt.Errorf("VCF %s:%d: Ref %s does not match reference",
+				x.Chrom, x.Pos+1, x.Ref)
+		}
+		r = append(r, ref[p:x.Pos]...)
+		r = append(r, x.Alt[0]...)
+		p = end
+	}
+	return append(r, ref[p:]...), nil
+}
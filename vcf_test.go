@@ -0,0 +1,138 @@
+package bio_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/soniakeys/bio"
+)
+
+const vcfTestData = `##fileformat=VCFv4.2
+##contig=<ID=chr1,length=20>
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Depth">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	s1	s2
+chr1	3	rs1	G	T	50	PASS	DP=14;DB	GT:DP	0|1:7	1/1:7
+chr1	8	.	CAT	C	.	.	.	GT	./1	0/0
+chr1	15	.	A	AGG,AT	20.5	q10	DP=3	GT	1/2	0|0
+`
+
+func ExampleVCFReader() {
+	r, err := bio.NewVCFReader(strings.NewReader(vcfTestData))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(r.Header.FileFormat(), r.Header.Samples)
+	for {
+		v, err := r.ReadRecord()
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		dp, ok := v.InfoValue("DP")
+		fmt.Println(v.Chrom, v.Pos, v.ID, v.Ref, v.Alt, v.Qual, v.Filter,
+			dp, ok)
+		for s, name := range r.Header.Samples {
+			gt, phased, _ := v.Genotype(s)
+			fmt.Println("  ", name, gt, phased)
+		}
+	}
+	// Output:
+	// VCFv4.2 [s1 s2]
+	// chr1 2 [rs1] G [T] 50 [PASS] 14 true
+	//    s1 [0 1] true
+	//    s2 [1 1] false
+	// chr1 7 [] CAT [C] NaN []  false
+	//    s1 [-1 1] false
+	//    s2 [0 0] false
+	// chr1 14 [] A [AGG AT] 20.5 [q10] 3 true
+	//    s1 [1 2] false
+	//    s2 [0 0] true
+	// EOF
+}
+
+func ExampleVCFWriter() {
+	h, v, err := bio.ReadVCF(strings.NewReader(vcfTestData))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	w := bio.NewVCFWriter(os.Stdout, h)
+	for _, r := range v {
+		w.WriteRecord(r)
+	}
+	w.Flush()
+	// Output:
+	// ##fileformat=VCFv4.2
+	// ##contig=<ID=chr1,length=20>
+	// ##INFO=<ID=DP,Number=1,Type=Integer,Description="Depth">
+	// #CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	s1	s2
+	// chr1	3	rs1	G	T	50	PASS	DP=14;DB	GT:DP	0|1:7	1/1:7
+	// chr1	8	.	CAT	C	.	.	.	GT	./1	0/0
+	// chr1	15	.	A	AGG,AT	20.5	q10	DP=3	GT	1/2	0|0
+}
+
+func ExampleCallSNPs() {
+	ref := bio.DNA8("ACGTACGTAC")
+	s := bio.DNA8("ACcTACGTAG")
+	w := bio.NewVCFWriter(os.Stdout, bio.VCFHeader{})
+	for _, v := range bio.CallSNPs("chr1", ref, s) {
+		w.WriteRecord(v)
+	}
+	w.Flush()
+	// Output:
+	// ##fileformat=VCFv4.2
+	// #CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO
+	// chr1	3	.	G	C	.	.	.
+	// chr1	10	.	C	G	.	.	.
+}
+
+func ExampleApplyVariants() {
+	ref := bio.DNA8("ACGTACGCATAAAAAAAAAA")
+	_, v, err := bio.ReadVCF(strings.NewReader(vcfTestData))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(bio.ApplyVariants(ref, v))
+	fmt.Println(bio.ApplyVariants(ref, v[:1]))
+	fmt.Println(bio.ApplyVariants(ref[:10], v))
+	// Output:
+	// ACTTACGCAAAAAGGAAAAA <nil>
+	// ACTTACGCATAAAAAAAAAA <nil>
+	//  VCF chr1:15: beyond end of reference
+}
+
+func TestVCFColumns(t *testing.T) {
+	h := strings.Join(strings.Split(vcfTestData, "\n")[:4], "\n") + "\n"
+	for _, c := range []struct {
+		header, line string
+		ok           bool
+	}{
+		{h, "chr1\t3\t.\tG\tT\t.\t.\t.\tGT\t0/1\t1/1", true},
+		{h, "chr1\t3\t.\tG\tT\t.\t.\t.\tGT\t0/1", false},
+		{h, "chr1\t3\t.\tG\tT\t.\t.\t.", false},
+		{h, "chr1\t3\t.\tG\tT\t.\t.\t.\tGT\t0/1\t1/1\t0/0", false},
+		{"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n",
+			"chr1\t3\t.\tG\tT\t.\t.\t.", true},
+		{"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n",
+			"chr1\t3\t.\tG\tT\t.\t.\t.\tGT\t0/1", false},
+	} {
+		_, _, err := bio.ReadVCF(strings.NewReader(c.header + c.line + "\n"))
+		if (err == nil) != c.ok {
+			t.Errorf("%q: err = %v", c.line, err)
+		}
+	}
+}
+
+func TestCallSNPsLength(t *testing.T) {
+	ref := bio.DNA8("ACGTACGTAC")
+	for _, s := range []bio.DNA8{bio.DNA8("ACCT"), bio.DNA8("ACCTACGTACGG")} {
+		v := bio.CallSNPs("chr1", ref, s)
+		if len(v) != 1 || v[0].Pos != 2 {
+			t.Errorf("%s: %v", s, v)
+		}
+	}
+}